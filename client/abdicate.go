@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/pkg/errors"
+)
+
+// ErrNotKing is returned when an account that isn't the king tries to abdicate.
+var ErrNotKing = errors.New("the account is not the king")
+
+// Abdicate ends the reign of the king, who collects the compensation at once, and resets the throne to the init price.
+// It returns the compensation paid by the app, in the asset for a throne priced in an ASA.
+func Abdicate(ctx context.Context, algodClient *algod.Client, king Account, appID uint64, waitRounds uint64) (uint64, error) {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return 0, err
+	}
+
+	if state.King != king.Address.String() {
+		return 0, errors.Wrapf(ErrNotKing, "%s of app %d", king.Address, appID)
+	}
+
+	// The king pays for the fee of the inner tx of the compensation.
+	params := CallParams{MinFees: 2, WaitRounds: waitRounds}
+	if state.AssetID != 0 {
+		params.ForeignAssets = []uint64{state.AssetID}
+		compensation, _, err := NewKingOfAlgoASAClient(algodClient, appID).Abdicate(ctx, king, params)
+
+		return compensation, err
+	}
+
+	compensation, _, err := NewKingOfAlgoClient(algodClient, appID).Abdicate(ctx, king, params)
+
+	return compensation, err
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+// abiReturnPrefix starts the log of the return value of an ARC-4 method.
+var abiReturnPrefix = []byte{0x15, 0x1f, 0x7c, 0x75}
+
+// Contract returns the ARC-4 contract of the given version, the variant priced in an ASA when the asset ID isn't zero.
+func Contract(version string, assetID uint64) (abi.Contract, error) {
+	load := contracts.Contract
+	if assetID != 0 {
+		load = contracts.ASAContract
+	}
+
+	contractJSON, err := load(version)
+	if err != nil {
+		return abi.Contract{}, err
+	}
+
+	contract := abi.Contract{}
+	err = json.Unmarshal(contractJSON, &contract)
+	if err != nil {
+		return abi.Contract{}, errors.Wrapf(err, "contract %s", version)
+	}
+
+	return contract, nil
+}
+
+// MethodResult is the decoded return value of a method call, nil for a void method.
+type MethodResult struct {
+	TxID        string
+	Round       uint64
+	ReturnValue interface{}
+	// Info is the pending transaction info of the method call, with its inner txs and logs.
+	Info models.PendingTransactionInfoResponse
+}
+
+// sendComposer signs and sends the group of the composer, whose method call is the last tx, and decodes its return value.
+func sendComposer(
+	ctx context.Context,
+	algodClient *algod.Client,
+	sender Account,
+	atc *transaction.AtomicTransactionComposer,
+	method abi.Method,
+	waitRounds uint64,
+) (MethodResult, error) {
+	signedBytes, signedGroup, err := signComposer(ctx, sender, atc)
+	if err != nil {
+		return MethodResult{}, err
+	}
+
+	_, err = sendWaitTransaction(ctx, algodClient, signedBytes, waitRounds)
+	if err != nil {
+		return MethodResult{}, err
+	}
+
+	return methodResult(ctx, algodClient, method, signedGroup[len(signedGroup)-1].Txn)
+}
+
+// signComposer builds the group of the composer and signs it with the account, like signTransactions.
+func signComposer(ctx context.Context, account Account, atc *transaction.AtomicTransactionComposer) ([]byte, []types.SignedTxn, error) {
+	group, err := atc.BuildGroup()
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	txs := make([]types.Transaction, len(group))
+	for i, tx := range group {
+		txs[i] = tx.Txn
+	}
+
+	return signTransactions(ctx, account, txs)
+}
+
+// methodResult reads the confirmed method call and decodes the return value it logged last.
+func methodResult(ctx context.Context, algodClient *algod.Client, method abi.Method, tx types.Transaction) (MethodResult, error) {
+	txID := crypto.GetTxID(tx)
+	info, _, err := algodClient.PendingTransactionInformation(txID).Do(ctx)
+	if err != nil {
+		return MethodResult{}, errors.WithStack(err)
+	}
+
+	result := MethodResult{TxID: txID, Round: info.ConfirmedRound, Info: info}
+	if method.Returns.IsVoid() {
+		return result, nil
+	}
+
+	if len(info.Logs) == 0 || !bytes.HasPrefix(info.Logs[len(info.Logs)-1], abiReturnPrefix) {
+		return result, errors.Errorf("method %s didn't log a return value", method.Name)
+	}
+
+	returnType, err := method.Returns.GetTypeObject()
+	if err != nil {
+		return result, errors.WithStack(err)
+	}
+
+	result.ReturnValue, err = returnType.Decode(info.Logs[len(info.Logs)-1][len(abiReturnPrefix):])
+	if err != nil {
+		return result, errors.Wrapf(err, "return value of %s", method.Name)
+	}
+
+	return result, nil
+}
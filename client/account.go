@@ -0,0 +1,206 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/kmd"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// Role is what an account is used for, it picks the default account of an operation.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RolePlayer Role = "player"
+	RoleBot    Role = "bot"
+)
+
+// Account is an address and the signer of its transactions, the private key may live outside of the process.
+type Account struct {
+	Name    string
+	Role    Role
+	Address types.Address
+	Signer  transaction.TransactionSigner
+}
+
+// NewAccount returns an account that signs with the given private key.
+func NewAccount(name string, role Role, account crypto.Account) Account {
+	return Account{
+		Name:    name,
+		Role:    role,
+		Address: account.Address,
+		Signer:  transaction.BasicAccountTransactionSigner{Account: account},
+	}
+}
+
+// contextSigner is a signer whose signing can be canceled with a context, like a remote service.
+type contextSigner interface {
+	SignTransactionsContext(ctx context.Context, txGroup []types.Transaction, indexesToSign []int) ([][]byte, error)
+}
+
+// signTransactions signs all the transactions with the signer of the account.
+// It returns the concatenated signed transactions ready to be sent and the decoded ones for debugging.
+func signTransactions(ctx context.Context, account Account, txs []types.Transaction) ([]byte, []types.SignedTxn, error) {
+	indexes := make([]int, len(txs))
+	for i := range txs {
+		indexes[i] = i
+	}
+
+	var signed [][]byte
+	var err error
+	if signer, ok := account.Signer.(contextSigner); ok {
+		signed, err = signer.SignTransactionsContext(ctx, txs, indexes)
+	} else {
+		signed, err = account.Signer.SignTransactions(txs, indexes)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "signing with account %s", account.Name)
+	}
+
+	signedBytes := []byte{}
+	signedGroup := []types.SignedTxn{}
+	for i, b := range signed {
+		signedTx := types.SignedTxn{}
+		err = msgpack.Decode(b, &signedTx)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		// The signer may be a remote service, make sure it signed what we asked for.
+		if !bytes.Equal(msgpack.Encode(signedTx.Txn), msgpack.Encode(txs[i])) {
+			return nil, nil, errors.Errorf("account %s signed a different transaction at index %d", account.Name, i)
+		}
+
+		signedBytes = append(signedBytes, b...)
+		signedGroup = append(signedGroup, signedTx)
+	}
+
+	return signedBytes, signedGroup, nil
+}
+
+// kmdSigner signs with a key held by a KMD wallet.
+type kmdSigner struct {
+	client         kmd.Client
+	walletID       string
+	walletPassword string
+	address        types.Address
+}
+
+func (s kmdSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	handle, err := s.client.InitWalletHandle(s.walletID, s.walletPassword)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer s.client.ReleaseWalletHandle(handle.WalletHandleToken)
+
+	result := make([][]byte, len(indexesToSign))
+	for i, pos := range indexesToSign {
+		resp, err := s.client.SignTransactionWithSpecificPublicKey(handle.WalletHandleToken, s.walletPassword, txGroup[pos], s.address[:])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		result[i] = resp.SignedTransaction
+	}
+
+	return result, nil
+}
+
+func (s kmdSigner) Equals(other transaction.TransactionSigner) bool {
+	o, ok := other.(kmdSigner)
+	return ok && o.walletID == s.walletID && o.address == s.address
+}
+
+// remoteSigner sends the transactions to an HTTP signing service.
+//
+// The service receives POST {"address": "...", "transactions": [base64 msgpack txn, ...]}
+// and answers {"signed": [base64 msgpack signed txn, ...]} in the same order.
+type remoteSigner struct {
+	endpoint string
+	apiToken string
+	address  types.Address
+	http     *http.Client
+}
+
+func newRemoteSigner(endpoint string, apiToken string, address types.Address) remoteSigner {
+	return remoteSigner{
+		endpoint: endpoint,
+		apiToken: apiToken,
+		address:  address,
+		http:     &http.Client{Timeout: time.Second * 30},
+	}
+}
+
+type remoteSignRequest struct {
+	Address      string   `json:"address"`
+	Transactions [][]byte `json:"transactions"`
+}
+
+type remoteSignResponse struct {
+	Signed [][]byte `json:"signed"`
+}
+
+func (s remoteSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	return s.SignTransactionsContext(context.Background(), txGroup, indexesToSign)
+}
+
+// SignTransactionsContext signs like SignTransactions, the call to the service is canceled with the context.
+func (s remoteSigner) SignTransactionsContext(ctx context.Context, txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	req := remoteSignRequest{Address: s.address.String()}
+	for _, pos := range indexesToSign {
+		req.Transactions = append(req.Transactions, msgpack.Encode(txGroup[pos]))
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.apiToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.apiToken)
+	}
+
+	httpResp, err := s.http.Do(httpReq)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("remote signer answered %s", httpResp.Status)
+	}
+
+	resp := remoteSignResponse{}
+	err = json.NewDecoder(httpResp.Body).Decode(&resp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(resp.Signed) != len(indexesToSign) {
+		return nil, errors.Errorf("remote signer returned %d transactions, expected %d", len(resp.Signed), len(indexesToSign))
+	}
+
+	return resp.Signed, nil
+}
+
+func (s remoteSigner) Equals(other transaction.TransactionSigner) bool {
+	o, ok := other.(remoteSigner)
+	return ok && o.endpoint == s.endpoint && o.address == s.address
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// ErrNotAdmin is returned when an account calls an admin method of an app it isn't the admin of.
+var ErrNotAdmin = errors.New("the account is not the admin")
+
+// ErrNoPendingAdmin is returned when accepting the admin role of an app without a proposed admin.
+var ErrNoPendingAdmin = errors.New("there is no pending admin")
+
+// ProposeAdmin proposes the next admin of the app, who becomes admin once it accepts with AcceptAdmin.
+// Proposing again replaces the pending admin.
+func ProposeAdmin(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, newAdmin string, waitRounds uint64) error {
+	newAdminAddress, err := types.DecodeAddress(newAdmin)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	_, err = adminClient(algodClient, appID).ProposeAdmin(ctx, admin, CallParams{MinFees: 1, WaitRounds: waitRounds}, newAdminAddress)
+
+	return err
+}
+
+// AcceptAdmin makes the pending admin the admin of the app: the admin fees of the next claims go to it
+// and it holds the update and delete rights.
+func AcceptAdmin(ctx context.Context, algodClient *algod.Client, pendingAdmin Account, appID uint64, waitRounds uint64) error {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return err
+	}
+
+	if state.PendingAdmin == "" {
+		return errors.Wrapf(ErrNoPendingAdmin, "app %d", appID)
+	}
+
+	if state.PendingAdmin != pendingAdmin.Address.String() {
+		return errors.Errorf("%s is not the pending admin of app %d", pendingAdmin.Address, appID)
+	}
+
+	// The admin fees of a throne priced in an ASA are transfers of the asset.
+	if state.AssetID != 0 {
+		_, err = assetHolding(ctx, algodClient, pendingAdmin.Address.String(), state.AssetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = adminClient(algodClient, appID).AcceptAdmin(ctx, pendingAdmin, CallParams{MinFees: 1, WaitRounds: waitRounds})
+
+	return err
+}
+
+// CancelAdminProposal removes the pending admin of the app.
+func CancelAdminProposal(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, waitRounds uint64) error {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	if state.PendingAdmin == "" {
+		return errors.Wrapf(ErrNoPendingAdmin, "app %d", appID)
+	}
+
+	_, err = adminClient(algodClient, appID).CancelAdmin(ctx, admin, CallParams{MinFees: 1, WaitRounds: waitRounds})
+
+	return err
+}
+
+// adminClient calls the admin methods of the app, the variants share them so the client of either calls them.
+func adminClient(algodClient *algod.Client, appID uint64) KingOfAlgoClient {
+	return NewKingOfAlgoClient(algodClient, appID)
+}
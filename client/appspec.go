@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// The typed clients, the deploy helpers and AppGlobalState are generated from the app specs of the contracts.
+//go:generate go run ../cmd/appspecgen -package client -out appspec_gen.go ../contracts/KingOfAlgo.arc56.json ../contracts/KingOfAlgoASA.arc56.json
+
+// CallParams are what a method call needs beyond its args.
+type CallParams struct {
+	// MinFees is the number of min fees the call pays, covering its inner txs, zero keeps the suggested fee.
+	MinFees         uint64
+	ForeignAccounts []string
+	ForeignAssets   []uint64
+	// Note defaults to the note of the method.
+	Note       []byte
+	WaitRounds uint64
+}
+
+// appClient calls the methods of an app by signature, the generated clients wrap it with a typed method per method.
+type appClient struct {
+	algod *algod.Client
+	appID uint64
+}
+
+// AppID is the ID of the app the client calls.
+func (c appClient) AppID() uint64 {
+	return c.appID
+}
+
+// add adds the method call to the composer, the txs of its args go before it in the group.
+func (c appClient) add(
+	atc *transaction.AtomicTransactionComposer,
+	sender Account,
+	suggestedParams types.SuggestedParams,
+	params CallParams,
+	signature string,
+	methodArgs ...interface{},
+) error {
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if params.MinFees > 0 {
+		suggestedParams.FlatFee = true
+		suggestedParams.Fee = transaction.MinTxnFee * types.MicroAlgos(params.MinFees)
+	}
+
+	note := params.Note
+	if note == nil {
+		note = []byte(fmt.Sprintf(noteFormat, method.Name))
+	}
+
+	err = atc.AddMethodCall(transaction.AddMethodCallParams{
+		AppID:           c.appID,
+		Method:          method,
+		MethodArgs:      methodArgs,
+		Sender:          sender.Address,
+		SuggestedParams: suggestedParams,
+		OnComplete:      types.NoOpOC,
+		Note:            note,
+		Signer:          sender.Signer,
+		ForeignAccounts: params.ForeignAccounts,
+		ForeignAssets:   params.ForeignAssets,
+	})
+
+	return errors.WithStack(err)
+}
+
+// call sends the method call with the txs of its args and decodes its return value.
+func (c appClient) call(ctx context.Context, sender Account, params CallParams, signature string, methodArgs ...interface{}) (MethodResult, error) {
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		return MethodResult{}, errors.WithStack(err)
+	}
+
+	suggestedParams, err := c.algod.SuggestedParams().Do(ctx)
+	if err != nil {
+		return MethodResult{}, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, c.algod, suggestedParams)
+	if err != nil {
+		return MethodResult{}, err
+	}
+
+	atc := transaction.AtomicTransactionComposer{}
+	err = c.add(&atc, sender, suggestedParams, params, signature, methodArgs...)
+	if err != nil {
+		return MethodResult{}, err
+	}
+
+	return sendComposer(ctx, c.algod, sender, &atc, method, params.WaitRounds)
+}
+
+// encodeMethodArgs encodes the app args of a method call, for the calls the composer can't build like the creation.
+func encodeMethodArgs(signature string, methodArgs ...interface{}) ([][]byte, error) {
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(methodArgs) != len(method.Args) {
+		return nil, errors.Errorf("method %s takes %d args, got %d", method.Name, len(method.Args), len(methodArgs))
+	}
+
+	appArgs := [][]byte{method.GetSelector()}
+	for i, arg := range method.Args {
+		argType, err := arg.GetTypeObject()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		encoded, err := argType.Encode(methodArgs[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "arg %s of %s", arg.Name, method.Name)
+		}
+
+		appArgs = append(appArgs, encoded)
+	}
+
+	return appArgs, nil
+}
+
+// decodeBytes decodes a bytes value of the global state.
+func decodeBytes(b string) ([]byte, error) {
+	value, err := base64.StdEncoding.DecodeString(b)
+
+	return value, errors.WithStack(err)
+}
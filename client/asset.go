@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNotOptedIn is returned when an account of a claim can't hold the asset of the throne.
+var ErrNotOptedIn = errors.New("account is not opted in to the asset")
+
+// DeployASA creates a throne priced in the given asset, the init price is in base units of the asset.
+// The app account is funded for the asset holding and opted in before the first claim.
+func DeployASA(
+	ctx context.Context,
+	algodClient *algod.Client,
+	account Account,
+	registry *Registry,
+	name string,
+	reignPeriod time.Duration,
+	upgradeDelay time.Duration,
+	assetID uint64,
+	initPrice uint64,
+	creationNote string,
+) (appID uint64, err error) {
+	ctx, span := startSpan(ctx, "DeployASA", senderAttr(account.Address), attribute.String("name", name), attribute.Int64("asset_id", int64(assetID)))
+	defer func() { endSpan(span, err) }()
+
+	if initPrice == 0 {
+		return 0, errors.New("the init price has to be above 0")
+	}
+
+	compiledApprovalProgram, compiledClearProgram, err := compileASAPrograms(ctx, algodClient, contracts.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	gSchema, lSchema := KingOfAlgoASAStateSchemas()
+
+	createArgs, err := KingOfAlgoASACreateArgs(uint64(reignPeriod.Seconds()), assetID, initPrice, uint64(upgradeDelay.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+
+	return deployApp(ctx, algodClient, account, registry, appDeployment{
+		name:            name,
+		reignPeriod:     reignPeriod,
+		creationNote:    creationNote,
+		assetID:         assetID,
+		approvalProgram: compiledApprovalProgram,
+		clearProgram:    compiledClearProgram,
+		globalSchema:    gSchema,
+		localSchema:     lSchema,
+		args:            createArgs,
+		// The app account holds the asset, which raises its min balance.
+		initBalance: MinBalance * 2,
+	})
+}
+
+// optInAppToAsset asks the app to opt its account in to the asset, the admin pays for the fee of the inner tx.
+func optInAppToAsset(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, assetID uint64, waitRounds uint64) error {
+	_, err := NewKingOfAlgoASAClient(algodClient, appID).OptInAsset(ctx, admin, CallParams{
+		MinFees:       2,
+		ForeignAssets: []uint64{assetID},
+		WaitRounds:    waitRounds,
+	})
+
+	return err
+}
+
+// CheckAssetOptIns checks the accounts of the next claim of a throne priced in an ASA can transfer the asset:
+// the claimant holds the claim price and the admin is opted in. A previous king who opted out doesn't block the claim,
+// see KingOptedOut. It does nothing for a throne priced in ALGO.
+func CheckAssetOptIns(ctx context.Context, algodClient *algod.Client, state State, claimant string) error {
+	if state.AssetID == 0 {
+		return nil
+	}
+
+	holding, err := assetHolding(ctx, algodClient, claimant, state.AssetID)
+	if err != nil {
+		return errors.Wrap(err, "claimant")
+	}
+
+	if holding < state.ClaimPrice() {
+		return errors.Errorf("claimant %s holds %d of asset %d, the claim costs %d", claimant, holding, state.AssetID, state.ClaimPrice())
+	}
+
+	_, err = assetHolding(ctx, algodClient, state.Admin, state.AssetID)
+	if err != nil {
+		return errors.Wrap(err, "admin")
+	}
+
+	return nil
+}
+
+// KingOptedOut tells if the king of a throne priced in an ASA opted out of the asset: the reward of the next claim
+// then goes to the app account, and the compensation stays there. It is false for a throne priced in ALGO.
+func KingOptedOut(ctx context.Context, algodClient *algod.Client, state State) (bool, error) {
+	if state.AssetID == 0 || state.King == "" {
+		return false, nil
+	}
+
+	_, err := assetHolding(ctx, algodClient, state.King, state.AssetID)
+	if errors.Is(err, ErrNotOptedIn) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "king")
+	}
+
+	return false, nil
+}
+
+// assetHolding returns the amount of the asset held by the account.
+func assetHolding(ctx context.Context, algodClient *algod.Client, address string, assetID uint64) (uint64, error) {
+	info, err := algodClient.AccountAssetInformation(address, assetID).Do(ctx)
+	if err != nil {
+		// The SDK only tells the status code in the message.
+		if strings.HasPrefix(err.Error(), "HTTP 404") {
+			return 0, errors.Wrapf(ErrNotOptedIn, "%s to %d", address, assetID)
+		}
+
+		return 0, errors.WithStack(err)
+	}
+
+	return info.AssetHolding.Amount, nil
+}
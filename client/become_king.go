@@ -13,9 +13,19 @@ import (
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-const noteFormat = "kingOfAlgo/v1:u%s"
+const noteFormat = "kingOfAlgo/" + contracts.Version + ":u%s"
+
+// ClaimResult is the info of the confirmed claim call and the fees the claim group paid.
+type ClaimResult struct {
+	models.PendingTransactionInfoResponse
+	Fees Fees
+	// Crown is the crown the claim minted for the new king, zero for a throne priced in an ASA.
+	Crown uint64
+}
 
 func BecomeKing(
 	ctx context.Context,
@@ -23,183 +33,374 @@ func BecomeKing(
 	debug bool,
 	params BecomeKingParams,
 	waitRounds uint64,
-) (models.PendingTransactionInfoResponse, error) {
-	signedTxnBytes, signedGroup, err := MakeBecomeKingTx(params)
+) (result ClaimResult, err error) {
+	ctx, span := startSpan(ctx, "BecomeKing", appIDAttr(params.appIndex), senderAttr(params.sender.Address))
+	defer func() { endSpan(span, err) }()
+
+	err = checkNetwork(ctx, client, params.txParams)
 	if err != nil {
-		return models.PendingTransactionInfoResponse{}, errors.WithStack(err)
+		return ClaimResult{}, err
+	}
+
+	err = CheckAssetOptIns(ctx, client, params.state, params.sender.Address.String())
+	if err != nil {
+		return ClaimResult{}, err
+	}
+
+	params.kingOptedOut, err = KingOptedOut(ctx, client, params.state)
+	if err != nil {
+		return ClaimResult{}, err
 	}
 
-	if debug {
-		err = debugTxn(ctx, client, signedGroup)
+	logger := loggerFrom(ctx).With("app_id", params.appIndex, "sender", params.sender.Address.String())
+
+	params = params.withWindow(waitRounds)
+	for resubmits := 0; ; resubmits++ {
+		logger.InfoContext(ctx, "claiming the throne", "reign", params.state.Reign+1, "price", params.getPayAmount(), "fee_mode", params.fees.Mode)
+
+		signedTxnBytes, signedGroup, fees, err := makeBecomeKingGroup(ctx, params)
 		if err != nil {
-			return models.PendingTransactionInfoResponse{}, errors.WithStack(err)
+			return ClaimResult{}, errors.WithStack(err)
+		}
+
+		if debug {
+			err = debugTxn(ctx, client, signedGroup)
+			if err != nil {
+				return ClaimResult{}, errors.WithStack(err)
+			}
+		}
+
+		// The info of the claim call, with the inner txs of the contract. The window is the rounds to wait.
+		resp, err := submitGroup(ctx, client, params.journalEntry(), signedTxnBytes, 0, params.submit)
+		if err == nil {
+			span.SetAttributes(roundAttr(resp.ConfirmedRound))
+			logger.InfoContext(ctx, "claimed the throne", "round", resp.ConfirmedRound, "fees", fees.Total)
+			return params.collectCrown(ctx, client, ClaimResult{PendingTransactionInfoResponse: resp, Fees: fees, Crown: mintedCrown(resp)}, waitRounds)
+		}
+
+		if !errors.Is(err, ErrExpired) || resubmits >= params.submit.Resubmits {
+			return ClaimResult{}, err
+		}
+
+		logger.WarnContext(ctx, "claim expired, resubmitting", "last_valid", uint64(params.txParams.LastRoundValid), "resubmit", resubmits+1)
+
+		params, err = params.renew(ctx, client, waitRounds)
+		if err != nil {
+			return ClaimResult{}, err
 		}
 	}
+}
 
-	return sendWaitTransaction(ctx, client, signedTxnBytes, waitRounds)
+// collectCrown has the new king collect the crown the claim minted, unless the params leave it in the app account.
+// The claim is confirmed even when collecting fails, the result tells which crown is left to collect.
+func (p BecomeKingParams) collectCrown(ctx context.Context, client *algod.Client, result ClaimResult, waitRounds uint64) (ClaimResult, error) {
+	if result.Crown == 0 || p.leaveCrown {
+		return result, nil
+	}
+
+	err := CollectCrown(ctx, client, p.sender, p.appIndex, result.Crown, waitRounds)
+	if err != nil {
+		return result, errors.Wrapf(err, "collect crown %d of app %d", result.Crown, p.appIndex)
+	}
+
+	return result, nil
 }
 
-// MakeBecomeKingTx creates the signed transactions to become king.
-func MakeBecomeKingTx(params BecomeKingParams) ([]byte, []types.SignedTxn, error) {
-	suggestedParams := params.txParams
-	// We need to give more fee for the inner tx to pay the previous king.
-	// When you do flat fee you can put whatever fee you want and in this case because we have one inner tx inside the contract
-	accounts := []string{}
-	if params.isReignEnded() && params.state.King != "" {
-		accounts = append(accounts, params.state.King)
-
-		// I could add all the fees in the first tx of the group (1000 * 5) but no, we put 2 * min in the appTx for the innertx and the suggested to the compTx, rewardTx and the adminFeeTx
-		suggestedParams.FlatFee = true
-		suggestedParams.Fee = transaction.MinTxnFee * 2
-	}
-
-	// NOOP TX.
-	noOpTx, err := transaction.MakeApplicationNoOpTx(
-		params.appIndex,
-		[][]byte{[]byte("claim_throne")},
-		accounts,
-		nil,
-		nil,
-		suggestedParams,
-		params.sender.Address,
-		[]byte(fmt.Sprintf(noteFormat, params.message)),
-		types.Digest{},
-		[32]byte{},
-		types.ZeroAddress,
-	)
+// renew rebuilds the params of an expired claim with a new validity window, when the state is still the one claimed.
+func (p BecomeKingParams) renew(ctx context.Context, client *algod.Client, waitRounds uint64) (BecomeKingParams, error) {
+	state, err := retry(ctx, p.submit, func() (State, error) {
+		return GetAppState(ctx, client, p.appIndex)
+	})
 	if err != nil {
-		return nil, nil, errors.WithStack(err)
+		return p, err
 	}
 
-	transactions := []types.Transaction{}
-	transactions = append(transactions, noOpTx)
+	if !sameClaim(p.state, state) {
+		return p, errors.Wrapf(ErrStateChanged, "app %d at reign %d", p.appIndex, state.Reign)
+	}
 
-	adminFee := multiplyPercentage(params.getPayAmount(), params.state.AdminFee)
+	p.txParams, err = retry(ctx, p.submit, func() (types.SuggestedParams, error) {
+		return client.SuggestedParams().Do(ctx)
+	})
+	if err != nil {
+		return p, err
+	}
 
-	// Payment of fee to contract admin.
-	adminFeeTx, err := transaction.MakePaymentTxn(
-		params.sender.Address.String(),
-		params.state.Admin,
-		adminFee,
-		[]byte(fmt.Sprintf(noteFormat, "admin_fee_tx")),
-		"",
-		params.txParams)
+	p.kingOptedOut, err = KingOptedOut(ctx, client, state)
 	if err != nil {
-		return nil, nil, errors.WithStack(err)
+		return p, err
 	}
 
-	transactions = append(transactions, adminFeeTx)
+	p.state = state
+
+	return p.withWindow(waitRounds), nil
+}
 
-	reward := multiplyPercentage(params.getPayAmount(), params.state.RewardMultiplier)
+// MakeBecomeKingTx creates the signed transactions to become king, the claim method call comes last in the group.
+func MakeBecomeKingTx(ctx context.Context, params BecomeKingParams) ([]byte, []types.SignedTxn, error) {
+	signedBytes, signedGroup, _, err := makeBecomeKingGroup(ctx, params)
+
+	return signedBytes, signedGroup, err
+}
+
+// makeBecomeKingGroup is MakeBecomeKingTx with the fees the strategy of the params picked.
+func makeBecomeKingGroup(ctx context.Context, params BecomeKingParams) (signedBytes []byte, signedGroup []types.SignedTxn, fees Fees, err error) {
+	_, span := startSpan(ctx, "MakeBecomeKingTx",
+		appIDAttr(params.appIndex),
+		senderAttr(params.sender.Address),
+		roundAttr(uint64(params.txParams.FirstRoundValid)),
+		attribute.Int64("reign", int64(params.state.Reign+1)),
+	)
+	defer func() {
+		if err == nil {
+			span.SetAttributes(groupSpanAttrs(signedGroup)...)
+		}
+		endSpan(span, err)
+	}()
+
+	if params.state.Paused {
+		return nil, nil, Fees{}, errors.Wrapf(ErrPaused, "app %d", params.appIndex)
+	}
+
+	claimParams := params.state.ClaimParams()
+	adminFee := multiplyPercentage(params.getPayAmount(), claimParams.AdminFee)
+
+	reward := multiplyPercentage(params.getPayAmount(), claimParams.RewardMultiplier)
 	if !params.isKingSet() {
 		reward = 0
 	}
 
-	// Compensation amount to the contracts address.
-	comp := params.getPayAmount() - adminFee - reward
+	// Compensation amount to the contracts address, with the min balance of the crown the claim mints.
+	comp := params.getPayAmount() - adminFee - reward + params.state.ClaimCrownFunding()
 
-	compTx, err := transaction.MakePaymentTxn(
-		params.sender.Address.String(),
-		crypto.GetApplicationAddress(params.appIndex).String(),
-		comp,
-		[]byte(fmt.Sprintf(noteFormat, "comp_tx")),
-		"",
-		params.txParams)
-	if err != nil {
-		return nil, nil, errors.WithStack(err)
-	}
+	return params.makeClaimGroup(ctx, adminFee, comp, reward)
+}
 
-	transactions = append(transactions, compTx)
+// makeClaim composes the transfers of the claim and the call of claim_throne, or claim_empty_throne when there is no king.
+func (p BecomeKingParams) makeClaim(adminFee uint64, comp uint64, reward uint64) (*transaction.AtomicTransactionComposer, error) {
+	// The contract pays the dead king with an inner tx, and the variant priced in an ASA reads the king's holding.
+	accounts := []string{}
+	if p.isKingSet() && (p.isReignEnded() || p.state.AssetID != 0) {
+		accounts = append(accounts, p.state.King)
+	}
 
-	// If there is no previous king we omit this tx.
-	if params.isKingSet() {
-		rewardTx, err := transaction.MakePaymentTxn(
-			params.sender.Address.String(),
-			params.state.King,
-			reward,
-			[]byte(fmt.Sprintf(noteFormat, "reward_tx")),
-			"",
-			params.txParams)
-		if err != nil {
-			return nil, nil, errors.WithStack(err)
-		}
+	// The contract transfers the asset to the dead king.
+	foreignAssets := []uint64{}
+	if p.state.AssetID != 0 {
+		foreignAssets = append(foreignAssets, p.state.AssetID)
+	}
 
-		transactions = append(transactions, rewardTx)
+	// Payment of fee to contract admin.
+	adminFeeTx, err := p.makeTransferWithSigner(p.state.Admin, adminFee, "admin_fee_tx")
+	if err != nil {
+		return nil, err
 	}
 
-	groupedTxs, err := transaction.AssignGroupID(transactions, "")
+	// Compensation amount to the contracts address.
+	compTx, err := p.makeTransferWithSigner(crypto.GetApplicationAddress(p.appIndex).String(), comp, "comp_tx")
 	if err != nil {
-		return nil, nil, errors.WithStack(err)
+		return nil, err
 	}
 
-	signedGrpBytes := []byte{}
-	signedGroup := []types.SignedTxn{}
-	signedTx := types.SignedTxn{}
-	for _, tx := range groupedTxs {
-		_, signedBytes, err := crypto.SignTransaction(params.sender.PrivateKey, tx)
-		if err != nil {
-			return nil, nil, errors.WithStack(err)
+	// If there is no previous king we omit this tx, the reward of a king who opted out of the asset stays in the app account.
+	rewardTx := transaction.TransactionWithSigner{}
+	if p.isKingSet() {
+		rewardReceiver := p.state.King
+		if p.kingOptedOut {
+			rewardReceiver = crypto.GetApplicationAddress(p.appIndex).String()
 		}
 
-		signedGrpBytes = append(signedGrpBytes, signedBytes...)
-
-		err = msgpack.Decode(signedBytes, &signedTx)
+		rewardTx, err = p.makeTransferWithSigner(rewardReceiver, reward, "reward_tx")
 		if err != nil {
-			return nil, nil, errors.WithStack(err)
+			return nil, err
 		}
+	}
+
+	// The contract writes the message in the metadata of the crown it mints, the variant priced in an ASA has no crown.
+	message := ""
+	if p.state.AssetID == 0 {
+		message = string(crownMessageArg(p.message))
+		if len(message) > crownMaxMessageLength {
+			return nil, errors.Errorf("the message of the crown is longer than %d bytes once encoded", crownMaxMessageLength)
+		}
+	}
 
-		signedGroup = append(signedGroup, signedTx)
+	params := CallParams{
+		ForeignAccounts: accounts,
+		ForeignAssets:   foreignAssets,
+		Note:            []byte(fmt.Sprintf(noteFormat, p.message)),
 	}
 
-	return signedGrpBytes, signedGroup, nil
+	atc := &transaction.AtomicTransactionComposer{}
+	if p.state.AssetID != 0 {
+		app := NewKingOfAlgoASAClient(nil, p.appIndex)
+		if p.isKingSet() {
+			err = app.AddClaimThrone(atc, p.sender, p.txParams, params, adminFeeTx, compTx, rewardTx)
+		} else {
+			err = app.AddClaimEmptyThrone(atc, p.sender, p.txParams, params, adminFeeTx, compTx)
+		}
+	} else {
+		app := NewKingOfAlgoClient(nil, p.appIndex)
+		if p.isKingSet() {
+			err = app.AddClaimThrone(atc, p.sender, p.txParams, params, adminFeeTx, compTx, rewardTx, message)
+		} else {
+			err = app.AddClaimEmptyThrone(atc, p.sender, p.txParams, params, adminFeeTx, compTx, message)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return atc, nil
 }
 
 type BecomeKingParams struct {
 	txParams types.SuggestedParams
 	state    State
-	sender   crypto.Account
+	sender   Account
 	message  string
 	appIndex uint64
+	// leaveCrown leaves the crown of the new king in the app account.
+	leaveCrown bool
+	// kingOptedOut sends the reward to the app account, the king of the throne priced in an ASA can't receive it.
+	kingOptedOut bool
+	fees         FeeStrategy
+	submit       SubmitOptions
 }
 
-func NewBecomeKingParams(txParams types.SuggestedParams, appIndex uint64, state State, sender crypto.Account, message string) BecomeKingParams {
+func NewBecomeKingParams(txParams types.SuggestedParams, appIndex uint64, state State, sender Account, message string) BecomeKingParams {
 	return BecomeKingParams{
 		txParams: txParams,
 		state:    state,
 		sender:   sender,
 		message:  message,
 		appIndex: appIndex,
+		submit:   DefaultSubmitOptions,
 	}
 }
 
-func (p BecomeKingParams) isReignEnded() bool {
-	return p.state.EndOfReign.Before(time.Now())
+// LeaveCrown leaves the crown the claim mints in the app account instead of collecting it,
+// the king can collect it later with CollectCrown.
+func (p BecomeKingParams) LeaveCrown() BecomeKingParams {
+	p.leaveCrown = true
+
+	return p
 }
 
-func (p BecomeKingParams) getPayAmount() uint64 {
-	if p.isReignEnded() {
-		return p.state.InitPrice
+// WithFees picks the fees of the claim group with the strategy, the min fees by default.
+func (p BecomeKingParams) WithFees(strategy FeeStrategy) BecomeKingParams {
+	p.fees = strategy
+
+	return p
+}
+
+// WithSubmitOptions retries the algod calls and resubmits an expired claim with the options, DefaultSubmitOptions by default.
+func (p BecomeKingParams) WithSubmitOptions(opts SubmitOptions) BecomeKingParams {
+	p.submit = opts
+
+	return p
+}
+
+// journalEntry is the intent of the claim in the journal.
+func (p BecomeKingParams) journalEntry() JournalEntry {
+	return JournalEntry{Kind: JournalClaim, AppID: p.appIndex, Reign: p.state.Reign + 1, Price: p.getPayAmount()}
+}
+
+// withWindow makes the claim valid for the rounds to wait, it expires when they pass.
+func (p BecomeKingParams) withWindow(waitRounds uint64) BecomeKingParams {
+	if waitRounds > 0 {
+		p.txParams.LastRoundValid = p.txParams.FirstRoundValid + types.Round(waitRounds)
 	}
 
-	return p.state.KingPrice
+	return p
+}
+
+func (p BecomeKingParams) isReignEnded() bool {
+	return p.state.IsReignEnded()
+}
+
+func (p BecomeKingParams) getPayAmount() uint64 {
+	return p.state.ClaimPrice()
 }
 
 func (p BecomeKingParams) isKingSet() bool {
 	return p.state.King != ""
 }
 
-func sendWaitTransaction(ctx context.Context, client *algod.Client, rawTxn []byte, waitRounds uint64) (models.PendingTransactionInfoResponse, error) {
-	txID, err := client.SendRawTransaction(rawTxn).Do(ctx)
+// makeTransfer pays the receiver in the currency of the throne, ALGO or its asset.
+func (p BecomeKingParams) makeTransfer(receiver string, amount uint64, note string) (types.Transaction, error) {
+	if p.state.AssetID != 0 {
+		return transaction.MakeAssetTransferTxn(
+			p.sender.Address.String(),
+			receiver,
+			amount,
+			[]byte(fmt.Sprintf(noteFormat, note)),
+			p.txParams,
+			"",
+			p.state.AssetID,
+		)
+	}
+
+	return transaction.MakePaymentTxn(
+		p.sender.Address.String(),
+		receiver,
+		amount,
+		[]byte(fmt.Sprintf(noteFormat, note)),
+		"",
+		p.txParams,
+	)
+}
+
+// makeClaimGroup composes the claim, sets the fees of the group with the fee strategy and signs it.
+func (p BecomeKingParams) makeClaimGroup(ctx context.Context, adminFee uint64, comp uint64, reward uint64) ([]byte, []types.SignedTxn, Fees, error) {
+	atc, err := p.makeClaim(adminFee, comp, reward)
 	if err != nil {
-		return models.PendingTransactionInfoResponse{}, errors.WithStack(err)
+		return nil, nil, Fees{}, err
 	}
 
-	confirmedTxn, err := transaction.WaitForConfirmation(client, txID, waitRounds, ctx)
+	group, err := atc.BuildGroup()
 	if err != nil {
-		return models.PendingTransactionInfoResponse{}, errors.WithStack(err)
+		return nil, nil, Fees{}, errors.WithStack(err)
+	}
+
+	txs := make([]types.Transaction, len(group))
+	for i, tx := range group {
+		txs[i] = tx.Txn
 	}
 
-	return confirmedTxn, nil
+	// Two claims of the account for the same reign can't be valid at once.
+	txs[len(txs)-1].Lease = claimLease(p.appIndex, p.sender.Address, p.state.Reign)
+
+	// The app call covers the fees of the inner txs the contract needs for the claim.
+	txs, fees, err := p.fees.apply(txs, p.txParams, p.state.ClaimInnerTxs())
+	if err != nil {
+		return nil, nil, Fees{}, err
+	}
+
+	signedBytes, signedGroup, err := signTransactions(ctx, p.sender, txs)
+	if err != nil {
+		return nil, nil, Fees{}, err
+	}
+
+	return signedBytes, signedGroup, fees, nil
+}
+
+// makeTransferWithSigner is makeTransfer as an arg of a method call, signed by the claimant.
+func (p BecomeKingParams) makeTransferWithSigner(receiver string, amount uint64, note string) (transaction.TransactionWithSigner, error) {
+	tx, err := p.makeTransfer(receiver, amount, note)
+	if err != nil {
+		return transaction.TransactionWithSigner{}, errors.WithStack(err)
+	}
+
+	return transaction.TransactionWithSigner{Txn: tx, Signer: p.sender.Signer}, nil
+}
+
+// sendWaitTransaction sends the signed group and waits for its last tx, retrying the transient algod errors.
+func sendWaitTransaction(ctx context.Context, client *algod.Client, rawTxn []byte, waitRounds uint64) (info models.PendingTransactionInfoResponse, err error) {
+	ctx, span := startSpan(ctx, "sendWaitTransaction")
+	defer func() { endSpan(span, err) }()
+
+	return sendWaitGroup(ctx, client, rawTxn, waitRounds, DefaultSubmitOptions)
 }
 
 func debugTxn(ctx context.Context, client *algod.Client, txns []types.SignedTxn) error {
@@ -240,7 +441,12 @@ func debugTxn(ctx context.Context, client *algod.Client, txns []types.SignedTxn)
 
 	for idx, txResult := range drresp.Txns {
 		if txResult.AppCallRejected() {
-			fmt.Printf("Failed app call in %d:\n%s", idx, txResult.GetAppCallTrace(transaction.DefaultStackPrinterConfig()))
+			loggerFrom(ctx).WarnContext(ctx, "app call rejected in the dry run",
+				"index", idx,
+				"tx_id", crypto.GetTxID(txns[idx].Txn),
+				"dryrun", filename,
+				"trace", txResult.GetAppCallTrace(transaction.DefaultStackPrinterConfig()),
+			)
 		}
 	}
 
@@ -254,44 +460,20 @@ func BecomeKingUnbalancedRewardsExploit(
 	params BecomeKingParams,
 	waitRounds uint64,
 ) (models.PendingTransactionInfoResponse, error) {
-	signedTxnBytes, _, err := MakeUnbalancedRewardsExploitTx(params)
+	err := checkNetwork(ctx, client, params.txParams)
 	if err != nil {
-		return models.PendingTransactionInfoResponse{}, errors.WithStack(err)
+		return models.PendingTransactionInfoResponse{}, err
 	}
 
-	return sendWaitTransaction(ctx, client, signedTxnBytes, waitRounds)
-}
-
-func MakeUnbalancedRewardsExploitTx(params BecomeKingParams) ([]byte, []types.SignedTxn, error) {
-	suggestedParams := params.txParams
-	accounts := []string{}
-	if params.isReignEnded() && params.state.King != "" {
-		accounts = append(accounts, params.state.King)
-		suggestedParams.FlatFee = true
-		suggestedParams.Fee = transaction.MinTxnFee * 2
-	}
-
-	// NOOP TX.
-	noOpTx, err := transaction.MakeApplicationNoOpTx(
-		params.appIndex,
-		[][]byte{[]byte("todo_operation")},
-		accounts,
-		nil,
-		nil,
-		suggestedParams,
-		params.sender.Address,
-		[]byte(fmt.Sprintf(noteFormat, params.message)),
-		types.Digest{},
-		[32]byte{},
-		types.ZeroAddress,
-	)
+	signedTxnBytes, _, err := MakeUnbalancedRewardsExploitTx(ctx, params)
 	if err != nil {
-		return nil, nil, errors.WithStack(err)
+		return models.PendingTransactionInfoResponse{}, errors.WithStack(err)
 	}
 
-	transactions := []types.Transaction{}
-	transactions = append(transactions, noOpTx)
+	return sendWaitTransaction(ctx, client, signedTxnBytes, waitRounds)
+}
 
+func MakeUnbalancedRewardsExploitTx(ctx context.Context, params BecomeKingParams) ([]byte, []types.SignedTxn, error) {
 	totalPayAmount := params.getPayAmount()
 
 	// Exploit the contract by unbalancing the rewards.
@@ -304,72 +486,7 @@ func MakeUnbalancedRewardsExploitTx(params BecomeKingParams) ([]byte, []types.Si
 	reward := multiplyPercentage(totalPayAmount, rewardPercentage)
 	comp := totalPayAmount * compensationPercentage
 
-	// Payment of fee to contract admin.
-	adminFeeTx, err := transaction.MakePaymentTxn(
-		params.sender.Address.String(),
-		params.state.Admin,
-		adminFee,
-		[]byte(fmt.Sprintf(noteFormat, "admin_fee_tx")),
-		"",
-		params.txParams)
-	if err != nil {
-		return nil, nil, errors.WithStack(err)
-	}
-
-	transactions = append(transactions, adminFeeTx)
-
-	compTx, err := transaction.MakePaymentTxn(
-		params.sender.Address.String(),
-		crypto.GetApplicationAddress(params.appIndex).String(),
-		comp,
-		[]byte(fmt.Sprintf(noteFormat, "comp_tx")),
-		"",
-		params.txParams)
-	if err != nil {
-		return nil, nil, errors.WithStack(err)
-	}
-
-	transactions = append(transactions, compTx)
-
-	// If there is no previous king we omit this tx.
-	if params.isKingSet() {
-		rewardTx, err := transaction.MakePaymentTxn(
-			params.sender.Address.String(),
-			params.state.King,
-			reward,
-			[]byte(fmt.Sprintf(noteFormat, "reward_tx")),
-			"",
-			params.txParams)
-		if err != nil {
-			return nil, nil, errors.WithStack(err)
-		}
-
-		transactions = append(transactions, rewardTx)
-	}
-
-	groupedTxs, err := transaction.AssignGroupID(transactions, "")
-	if err != nil {
-		return nil, nil, errors.WithStack(err)
-	}
-
-	signedGrpBytes := []byte{}
-	signedGroup := []types.SignedTxn{}
-	signedTx := types.SignedTxn{}
-	for _, tx := range groupedTxs {
-		_, signedBytes, err := crypto.SignTransaction(params.sender.PrivateKey, tx)
-		if err != nil {
-			return nil, nil, errors.WithStack(err)
-		}
-
-		signedGrpBytes = append(signedGrpBytes, signedBytes...)
-
-		err = msgpack.Decode(signedBytes, &signedTx)
-		if err != nil {
-			return nil, nil, errors.WithStack(err)
-		}
-
-		signedGroup = append(signedGroup, signedTx)
-	}
+	signedBytes, signedGroup, _, err := params.makeClaimGroup(ctx, adminFee, comp, reward)
 
-	return signedGrpBytes, signedGroup, nil
+	return signedBytes, signedGroup, err
 }
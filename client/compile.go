@@ -6,13 +6,64 @@ import (
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func compileProgram(ctx context.Context, client *algod.Client, sourceCode []byte) ([]byte, error) {
+func compileProgram(ctx context.Context, client *algod.Client, sourceCode []byte) (program []byte, err error) {
+	ctx, span := startSpan(ctx, "compileProgram", attribute.Int("source_size", len(sourceCode)))
+	defer func() { endSpan(span, err) }()
+
 	compileResult, err := client.TealCompile(sourceCode).Do(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	span.SetAttributes(attribute.String("hash", compileResult.Hash))
+
 	return base64.StdEncoding.DecodeString(compileResult.Result)
 }
+
+// compilePrograms compiles the approval and clear programs of the given contract version.
+func compilePrograms(ctx context.Context, client *algod.Client, version string) ([]byte, []byte, error) {
+	approvalProgram, clearProgram, err := contracts.Programs(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compileSources(ctx, client, approvalProgram, clearProgram)
+}
+
+// compileASAPrograms compiles the approval and clear programs of the variant priced in an ASA of the given contract version.
+func compileASAPrograms(ctx context.Context, client *algod.Client, version string) ([]byte, []byte, error) {
+	approvalProgram, clearProgram, err := contracts.ASAPrograms(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compileSources(ctx, client, approvalProgram, clearProgram)
+}
+
+// compileFactoryPrograms compiles the approval and clear programs of the factory of the given contract version.
+func compileFactoryPrograms(ctx context.Context, client *algod.Client, version string) ([]byte, []byte, error) {
+	approvalProgram, clearProgram, err := contracts.FactoryPrograms(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compileSources(ctx, client, approvalProgram, clearProgram)
+}
+
+func compileSources(ctx context.Context, client *algod.Client, approvalProgram []byte, clearProgram []byte) ([]byte, []byte, error) {
+	compiledApprovalProgram, err := compileProgram(ctx, client, approvalProgram)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	compiledClearProgram, err := compileProgram(ctx, client, clearProgram)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compiledApprovalProgram, compiledClearProgram, nil
+}
@@ -2,30 +2,45 @@ package client
 
 import (
 	"os"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/algorand/go-algorand-sdk/v2/client/kmd"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/jinzhu/configor"
+	"github.com/pkg/errors"
 )
 
 // Config holds the config.
 type Config struct {
-	PrivateKey    string
-	MnemonicWords string
-	Algod         struct {
-		Endpoint  string
-		APIToken  string
-		UserAgent string
-	}
-	Account2 struct {
-		PrivateKey    string
-		MnemonicWords string
-	}
-	Indexer struct {
-		Endpoint string
-		APIToken string
+	// Accounts are keyed by name, operations pick them by name or by role.
+	Accounts map[string]AccountConfig
+	Keystore struct {
+		Path string `default:"keystore.json"`
+		// Passphrase is where the passphrase comes from: prompt, env:NAME or file:PATH.
+		Passphrase string `default:"prompt"`
 	}
-	APPID       uint64
+	// Network is the name of the profile in use, e.g. mainnet, testnet, localnet or a custom one.
+	Network  string `default:"localnet"`
+	Profiles map[string]Profile
+	Registry string `default:"apps.json"`
+	// Journal is the write-ahead journal of the claims and deploys, reconciled before the next ones.
+	Journal     string `default:"journal.jsonl"`
 	ReignPeriod time.Duration
+	// Fees is the fee strategy of the claims.
+	Fees FeeStrategy
+	// LogLevel is the level of the logs on stderr: debug logs every algod call, info every group sent.
+	LogLevel string `default:"warn"`
+	Tracing  struct {
+		// Endpoint is the URL of the OTLP collector the spans are exported to over HTTP, like http://localhost:4318.
+		// The operations aren't traced without one.
+		Endpoint string
+	}
+
+	passphrase []byte
 }
 
 // NewConfig returns a new configuration struct.
@@ -43,5 +58,255 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	profile, ok := cfg.Profiles[cfg.Network]
+	if !ok {
+		return nil, errors.Errorf("no profile for network %s", cfg.Network)
+	}
+
+	profile, err = profile.withKnownGenesis(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.APPID == 0 && profile.AppName != "" {
+		registry, err := LoadRegistry(cfg.Registry)
+		if err != nil {
+			return nil, err
+		}
+
+		app, err := registry.Lookup(profile.GenesisHash, profile.AppName)
+		if err != nil {
+			return nil, err
+		}
+
+		profile.APPID = app.AppID
+	}
+
+	cfg.Profiles[cfg.Network] = profile
+
 	return &cfg, nil
 }
+
+// Profile returns the profile of the network in use.
+func (c *Config) Profile() Profile {
+	return c.Profiles[c.Network]
+}
+
+// AccountConfig tells what an account is used for and where its key comes from.
+type AccountConfig struct {
+	Role Role
+	// Source is one of keystore (the default), mnemonic, kmd or remote.
+	Source string
+	// Key is the name in the keystore, it defaults to the account name.
+	Key string
+	// MnemonicEnv is the environment variable holding the mnemonic words.
+	MnemonicEnv string
+	KMD         struct {
+		Endpoint    string
+		APIToken    string
+		Wallet      string
+		PasswordEnv string
+		Address     string
+	}
+	Remote struct {
+		Endpoint string
+		APIToken string
+		Address  string
+	}
+}
+
+// LoadAccount returns the account with the given name.
+func (c *Config) LoadAccount(name string) (Account, error) {
+	accCfg, ok := c.Accounts[name]
+	if !ok {
+		return Account{}, errors.Errorf("no account named %q in the config", name)
+	}
+
+	switch accCfg.Source {
+	case "keystore", "":
+		key := accCfg.Key
+		if key == "" {
+			key = name
+		}
+
+		acc, err := c.keystoreAccount(key)
+		if err != nil {
+			return Account{}, err
+		}
+
+		return NewAccount(name, accCfg.Role, acc), nil
+	case "mnemonic":
+		words, ok := os.LookupEnv(accCfg.MnemonicEnv)
+		if !ok {
+			return Account{}, errors.Errorf("%s is not set for account %q", accCfg.MnemonicEnv, name)
+		}
+
+		key, err := mnemonic.ToPrivateKey(words)
+		if err != nil {
+			return Account{}, errors.WithStack(err)
+		}
+
+		acc, err := crypto.AccountFromPrivateKey(key)
+		if err != nil {
+			return Account{}, errors.WithStack(err)
+		}
+
+		return NewAccount(name, accCfg.Role, acc), nil
+	case "kmd":
+		address, err := types.DecodeAddress(accCfg.KMD.Address)
+		if err != nil {
+			return Account{}, errors.WithStack(err)
+		}
+
+		kmdClient, err := kmd.MakeClient(accCfg.KMD.Endpoint, accCfg.KMD.APIToken)
+		if err != nil {
+			return Account{}, errors.WithStack(err)
+		}
+
+		walletID, err := findWallet(kmdClient, accCfg.KMD.Wallet)
+		if err != nil {
+			return Account{}, err
+		}
+
+		return Account{
+			Name:    name,
+			Role:    accCfg.Role,
+			Address: address,
+			Signer: kmdSigner{
+				client:         kmdClient,
+				walletID:       walletID,
+				walletPassword: os.Getenv(accCfg.KMD.PasswordEnv),
+				address:        address,
+			},
+		}, nil
+	case "remote":
+		address, err := types.DecodeAddress(accCfg.Remote.Address)
+		if err != nil {
+			return Account{}, errors.WithStack(err)
+		}
+
+		return Account{
+			Name:    name,
+			Role:    accCfg.Role,
+			Address: address,
+			Signer:  newRemoteSigner(accCfg.Remote.Endpoint, accCfg.Remote.APIToken, address),
+		}, nil
+	default:
+		return Account{}, errors.Errorf("unknown key source %q for account %q", accCfg.Source, name)
+	}
+}
+
+// AccountFor returns the named account, or the only account with the role when the name is empty.
+func (c *Config) AccountFor(name string, role Role) (Account, error) {
+	name, err := c.accountName(name, role)
+	if err != nil {
+		return Account{}, err
+	}
+
+	return c.LoadAccount(name)
+}
+
+// AccountAddress is like AccountFor but only returns the name and address, it never touches the secrets.
+func (c *Config) AccountAddress(name string, role Role) (string, string, error) {
+	name, err := c.accountName(name, role)
+	if err != nil {
+		return "", "", err
+	}
+
+	accCfg := c.Accounts[name]
+	switch accCfg.Source {
+	case "keystore", "":
+		keystore, err := OpenKeystore(c.Keystore.Path)
+		if err != nil {
+			return "", "", err
+		}
+
+		key := accCfg.Key
+		if key == "" {
+			key = name
+		}
+
+		acc, ok := keystore.Accounts[key]
+		if !ok {
+			return "", "", errors.Wrapf(ErrAccountNotFound, "%q", key)
+		}
+
+		return name, acc.Address, nil
+	case "kmd":
+		return name, accCfg.KMD.Address, nil
+	case "remote":
+		return name, accCfg.Remote.Address, nil
+	default:
+		// The address of a mnemonic can only be known from the secret.
+		acc, err := c.LoadAccount(name)
+		if err != nil {
+			return "", "", err
+		}
+
+		return name, acc.Address.String(), nil
+	}
+}
+
+func (c *Config) accountName(name string, role Role) (string, error) {
+	if name != "" {
+		if _, ok := c.Accounts[name]; !ok {
+			return "", errors.Errorf("no account named %q in the config", name)
+		}
+
+		return name, nil
+	}
+
+	names := []string{}
+	for accName, accCfg := range c.Accounts {
+		if accCfg.Role == role {
+			names = append(names, accName)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return "", errors.Errorf("no %s account in the config", role)
+	case 1:
+		return names[0], nil
+	default:
+		sort.Strings(names)
+		return "", errors.Errorf("%d %s accounts in the config (%s), choose one", len(names), role, strings.Join(names, ", "))
+	}
+}
+
+// keystoreAccount decrypts the account from the keystore, the passphrase is asked once.
+func (c *Config) keystoreAccount(key string) (crypto.Account, error) {
+	keystore, err := OpenKeystore(c.Keystore.Path)
+	if err != nil {
+		return crypto.Account{}, err
+	}
+
+	if c.passphrase == nil {
+		source, err := ParsePassphrase(c.Keystore.Passphrase)
+		if err != nil {
+			return crypto.Account{}, err
+		}
+
+		c.passphrase, err = source()
+		if err != nil {
+			return crypto.Account{}, err
+		}
+	}
+
+	return keystore.Account(key, c.passphrase)
+}
+
+func findWallet(kmdClient kmd.Client, name string) (string, error) {
+	wallets, err := kmdClient.ListWallets()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	for _, wallet := range wallets.Wallets {
+		if wallet.Name == name {
+			return wallet.ID, nil
+		}
+	}
+
+	return "", errors.Errorf("no KMD wallet named %q", name)
+}
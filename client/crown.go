@@ -0,0 +1,326 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// Params of the crowns set by the contract.
+const (
+	crownUnitName = "CROWN"
+	crownName     = "King of Algo Crown"
+	// crownMinBalance is what the app account holds per crown it created, the claim minting the crown pays it.
+	crownMinBalance = 100000
+	// crownMaxMessageLength is the max length of the JSON encoded message written in the crown.
+	crownMaxMessageLength = 256
+)
+
+// Crown is a crown NFT held by a king, the metadata is the ARC-69 note written on coronation.
+type Crown struct {
+	AssetID uint64
+	// AppID is the app that awarded the crown, Creator is its address.
+	AppID   uint64
+	Creator string
+	Reign   uint64
+	Price   uint64
+	Message string
+	// Err is set when the metadata of the crown can't be read.
+	Err error
+}
+
+// crownMetadata is the ARC-69 note of a crown.
+type crownMetadata struct {
+	Standard   string `json:"standard"`
+	Properties struct {
+		Message string `json:"message"`
+		Reign   uint64 `json:"reign"`
+		Price   uint64 `json:"price"`
+	} `json:"properties"`
+}
+
+// crownMessageArg encodes the message of the king as a JSON string, the contract writes it as is in the metadata.
+func crownMessageArg(message string) []byte {
+	// Encoding a string can't fail.
+	arg, _ := json.Marshal(message)
+
+	return arg
+}
+
+// CollectCrown opts the king in to the crown the app minted for it and has the app send it.
+func CollectCrown(ctx context.Context, algodClient *algod.Client, king Account, appID uint64, crownID uint64, waitRounds uint64) error {
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return err
+	}
+
+	err = optInCrown(ctx, algodClient, suggestedParams, king, crownID, waitRounds)
+	if err != nil {
+		return err
+	}
+
+	// The king pays for the fee of the inner tx sending the crown.
+	_, err = NewKingOfAlgoClient(algodClient, appID).CollectCrown(
+		ctx,
+		king,
+		CallParams{MinFees: 2, ForeignAssets: []uint64{crownID}, WaitRounds: waitRounds},
+		crownID,
+	)
+
+	return err
+}
+
+// UncollectedCrowns returns the crowns the app account still holds, only the ones minted for the king unless it's empty.
+func UncollectedCrowns(ctx context.Context, algodClient *algod.Client, appID uint64, king string) ([]uint64, error) {
+	info, err := algodClient.AccountInformation(crypto.GetApplicationAddress(appID).String()).Do(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	held := map[uint64]bool{}
+	for _, holding := range info.Assets {
+		held[holding.AssetId] = holding.Amount > 0
+	}
+
+	// The reserve of a crown is the king it was minted for.
+	crownIDs := []uint64{}
+	for _, asset := range info.CreatedAssets {
+		if held[asset.Index] && (king == "" || asset.Params.Reserve == king) {
+			crownIDs = append(crownIDs, asset.Index)
+		}
+	}
+
+	return crownIDs, nil
+}
+
+// BurnCrowns destroys the crowns the app account still holds once the announced delete can run,
+// their min balance goes to the admin. It returns the crowns burned.
+func BurnCrowns(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, waitRounds uint64) ([]uint64, error) {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return nil, errors.Errorf("%s is not the admin of app %d", admin.Address, appID)
+	}
+
+	err = checkDeleteAnnounced(state.PendingUpgrade, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	crownIDs, err := UncollectedCrowns(ctx, algodClient, appID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The admin pays for the fees of the inner txs destroying the crown and paying its min balance.
+	app := NewKingOfAlgoClient(algodClient, appID)
+	for i, crownID := range crownIDs {
+		_, err = app.BurnCrown(ctx, admin, CallParams{MinFees: 3, ForeignAssets: []uint64{crownID}, WaitRounds: waitRounds}, crownID)
+		if err != nil {
+			return crownIDs[:i], errors.Wrapf(err, "burn crown %d", crownID)
+		}
+	}
+
+	return crownIDs, nil
+}
+
+// mintedCrown returns the crown the claim minted for the new king, the asset created by its inner txs.
+func mintedCrown(info models.PendingTransactionInfoResponse) uint64 {
+	for _, innerTx := range info.InnerTxns {
+		if innerTx.AssetIndex != 0 {
+			return innerTx.AssetIndex
+		}
+	}
+
+	return 0
+}
+
+// optInCrown opts the claimant in to the crown unless it already holds it.
+func optInCrown(ctx context.Context, algodClient *algod.Client, suggestedParams types.SuggestedParams, claimant Account, crownID uint64, waitRounds uint64) error {
+	_, err := assetHolding(ctx, algodClient, claimant.Address.String(), crownID)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, ErrNotOptedIn) {
+		return err
+	}
+
+	tx, err := transaction.MakeAssetAcceptanceTxn(
+		claimant.Address.String(),
+		[]byte(fmt.Sprintf(noteFormat, "crown_opt_in")),
+		suggestedParams,
+		crownID,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	signedBytes, _, err := signTransactions(ctx, claimant, []types.Transaction{tx})
+	if err != nil {
+		return err
+	}
+
+	_, err = sendWaitTransaction(ctx, algodClient, signedBytes, waitRounds)
+
+	return err
+}
+
+// KnownThrones returns the apps of the registry on the network of the node and the apps of the factories.
+func KnownThrones(ctx context.Context, algodClient *algod.Client, registry *Registry, factoryIDs ...uint64) ([]uint64, error) {
+	version, err := algodClient.Versions().Do(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	appIDs := []uint64{}
+	for _, entry := range registry.Apps(base64.StdEncoding.EncodeToString(version.GenesisHash)) {
+		appIDs = append(appIDs, entry.AppID)
+	}
+
+	for _, factoryID := range factoryIDs {
+		thrones, err := ListThrones(ctx, algodClient, factoryID, StatesOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, throne := range thrones {
+			appIDs = append(appIDs, throne.AppID)
+		}
+	}
+
+	return appIDs, nil
+}
+
+// ListCrowns returns the crowns held by the account, with the metadata written on coronation.
+// Anyone can mint an asset named like a crown: only the assets created by one of the apps, with the account
+// as reserve like the contract sets it, are crowns. A crown whose metadata can't be read has its error set.
+func ListCrowns(ctx context.Context, algodClient *algod.Client, indexerClient *indexer.Client, address string, appIDs []uint64) ([]Crown, error) {
+	info, err := algodClient.AccountInformation(address).Do(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	apps := map[string]uint64{}
+	for _, appID := range appIDs {
+		apps[crypto.GetApplicationAddress(appID).String()] = appID
+	}
+
+	crowns := []Crown{}
+	for _, holding := range info.Assets {
+		if holding.Amount == 0 {
+			continue
+		}
+
+		asset, err := algodClient.GetAssetByID(holding.AssetId).Do(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		appID, ok := apps[asset.Params.Creator]
+		if !ok || asset.Params.Reserve != address {
+			continue
+		}
+
+		if asset.Params.UnitName != crownUnitName || asset.Params.Name != crownName {
+			continue
+		}
+
+		crown := Crown{
+			AssetID: asset.Index,
+			AppID:   appID,
+			Creator: asset.Params.Creator,
+		}
+
+		metadata, err := lookupCrownMetadata(ctx, indexerClient, asset.Index)
+		if err != nil {
+			crown.Err = err
+		} else {
+			crown.Reign = metadata.Properties.Reign
+			crown.Price = metadata.Properties.Price
+			crown.Message = metadata.Properties.Message
+		}
+
+		crowns = append(crowns, crown)
+	}
+
+	return crowns, nil
+}
+
+// lookupCrownMetadata reads the note of the last config of the crown, ARC-69 metadata is the latest one.
+func lookupCrownMetadata(ctx context.Context, indexerClient *indexer.Client, assetID uint64) (crownMetadata, error) {
+	var note []byte
+	nextToken := ""
+	for {
+		resp, err := indexerClient.LookupAssetTransactions(assetID).
+			TxType("acfg").
+			Limit(indexerPageSize).
+			NextToken(nextToken).
+			Do(ctx)
+		if err != nil {
+			return crownMetadata{}, errors.WithStack(err)
+		}
+
+		for _, tx := range resp.Transactions {
+			if configNote := lastConfigNote(tx, assetID); configNote != nil {
+				note = configNote
+			}
+		}
+
+		if len(resp.Transactions) < indexerPageSize || resp.NextToken == "" {
+			break
+		}
+
+		nextToken = resp.NextToken
+	}
+
+	if note == nil {
+		return crownMetadata{}, errors.Errorf("crown %d has no metadata", assetID)
+	}
+
+	metadata := crownMetadata{}
+	err := json.Unmarshal(note, &metadata)
+	if err != nil {
+		return crownMetadata{}, errors.Wrapf(err, "crown %d", assetID)
+	}
+
+	if metadata.Standard != "arc69" {
+		return crownMetadata{}, errors.Errorf("crown %d has no ARC-69 metadata", assetID)
+	}
+
+	return metadata, nil
+}
+
+// lastConfigNote returns the note of the last config of the asset in the tx, the indexer returns the
+// root tx of the inner txs of the app.
+func lastConfigNote(tx models.Transaction, assetID uint64) []byte {
+	var note []byte
+	if tx.Type == "acfg" && (tx.AssetConfigTransaction.AssetId == assetID || tx.CreatedAssetIndex == assetID) {
+		note = tx.Note
+	}
+
+	for _, innerTx := range tx.InnerTxns {
+		if innerNote := lastConfigNote(innerTx, assetID); innerNote != nil {
+			note = innerNote
+		}
+	}
+
+	return note
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// ErrReignActive is returned when decommissioning an app whose king is still reigning.
+var ErrReignActive = errors.New("the reign is still active")
+
+// DecommissionReport shows what the app account holds and who it belongs to.
+type DecommissionReport struct {
+	AppID      uint64
+	AppAddress string
+	Admin      string
+	King       string
+	EndOfReign time.Time
+	// AssetID is the ASA the throne is priced in, zero when it is priced in ALGO.
+	AssetID uint64
+	// Balance is the total amount held by the app account.
+	Balance uint64
+	// Compensation is the part of the balance owed to the current king, in base units of the asset for a throne
+	// priced in an ASA.
+	Compensation uint64
+	// Remainder is the ALGO that goes back to the admin, with the min balance of the uncollected crowns.
+	Remainder uint64
+	// AssetRemainder is the asset that goes back to the admin for a throne priced in an ASA, when there is no king
+	// or the king opted out of the asset.
+	AssetRemainder uint64
+	// Crowns is the number of crowns the app created, the app account can't be closed while they exist.
+	Crowns uint64
+	// Uncollected are the crowns the app account still holds, burned before the delete.
+	Uncollected []uint64
+	// Locked is what stays in the app account for the crowns the kings collected, it can't be closed.
+	Locked uint64
+	// Upgrade is the announced upgrade or delete, the app can only be deleted once a delete is announced and ready.
+	Upgrade *Upgrade
+}
+
+// IsReignActive tells if there is a king whose reign hasn't ended yet.
+func (r DecommissionReport) IsReignActive() bool {
+	return r.King != "" && r.EndOfReign.After(time.Now())
+}
+
+// InspectDecommission runs the pre-flight checks of Decommission without sending anything.
+func InspectDecommission(ctx context.Context, algodClient *algod.Client, appID uint64) (DecommissionReport, error) {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return DecommissionReport{}, err
+	}
+
+	appAddress := crypto.GetApplicationAddress(appID)
+	info, err := algodClient.AccountInformation(appAddress.String()).Do(ctx)
+	if err != nil {
+		return DecommissionReport{}, errors.WithStack(err)
+	}
+
+	uncollected, err := UncollectedCrowns(ctx, algodClient, appID, "")
+	if err != nil {
+		return DecommissionReport{}, err
+	}
+
+	report := DecommissionReport{
+		AppID:       appID,
+		AppAddress:  appAddress.String(),
+		Admin:       state.Admin,
+		King:        state.King,
+		EndOfReign:  state.EndOfReign,
+		AssetID:     state.AssetID,
+		Balance:     info.Amount,
+		Remainder:   info.Amount,
+		Crowns:      state.Crowns,
+		Uncollected: uncollected,
+		Upgrade:     state.PendingUpgrade,
+	}
+
+	// The app account of a throne priced in an ASA holds the compensation in the asset, its ALGO all go to the admin.
+	if state.AssetID != 0 {
+		return inspectASADecommission(ctx, algodClient, state, report)
+	}
+
+	// Everything above the min balance was paid by the current king, the crowns raise the min balance.
+	minBalance := MinBalance + crownMinBalance*state.Crowns
+	if state.King != "" && info.Amount > minBalance {
+		report.Compensation = info.Amount - minBalance
+		report.Remainder = minBalance
+	}
+
+	// Burning a crown returns its min balance to the admin, the collected ones keep theirs in the app account.
+	if collected := state.Crowns - uint64(len(uncollected)); collected > 0 {
+		report.Locked = MinBalance + crownMinBalance*collected
+		report.Remainder -= report.Locked
+	}
+
+	return report, nil
+}
+
+// inspectASADecommission reports the compensation of a throne priced in an ASA: the contract sends the asset it holds
+// to the king, or closes it to the admin when the king can't receive it.
+func inspectASADecommission(ctx context.Context, algodClient *algod.Client, state State, report DecommissionReport) (DecommissionReport, error) {
+	holding, err := assetHolding(ctx, algodClient, report.AppAddress, state.AssetID)
+	if err != nil {
+		return DecommissionReport{}, errors.Wrap(err, "app account")
+	}
+
+	optedOut, err := KingOptedOut(ctx, algodClient, state)
+	if err != nil {
+		return DecommissionReport{}, err
+	}
+
+	report.AssetRemainder = holding
+	if state.King != "" && !optedOut {
+		report.Compensation = holding
+		report.AssetRemainder = 0
+	}
+
+	return report, nil
+}
+
+// Decommission settles the current king's compensation and deletes the app.
+// It refuses to delete while a reign is active unless force is set, and before the announced delete is ready.
+func Decommission(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, force bool, waitRounds uint64) (DecommissionReport, error) {
+	report, err := InspectDecommission(ctx, algodClient, appID)
+	if err != nil {
+		return DecommissionReport{}, err
+	}
+
+	if report.Admin != admin.Address.String() {
+		return report, errors.Errorf("%s is not the admin of app %d", admin.Address, appID)
+	}
+
+	if report.IsReignActive() && !force {
+		return report, errors.WithStack(ErrReignActive)
+	}
+
+	err = checkDeleteAnnounced(report.Upgrade, appID)
+	if err != nil {
+		return report, err
+	}
+
+	// The app account can only be closed once the crowns it holds are burned.
+	if len(report.Uncollected) > 0 {
+		_, err = BurnCrowns(ctx, algodClient, admin, appID, waitRounds)
+		if err != nil {
+			return report, err
+		}
+
+		report, err = InspectDecommission(ctx, algodClient, appID)
+		if err != nil {
+			return DecommissionReport{}, err
+		}
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return report, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return report, err
+	}
+
+	signedBytes, err := makeDeleteAppTx(ctx, suggestedParams, admin, appID, report)
+	if err != nil {
+		return report, err
+	}
+
+	_, err = sendWaitTransaction(ctx, algodClient, signedBytes, waitRounds)
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func makeDeleteAppTx(ctx context.Context, suggestedParams types.SuggestedParams, admin Account, appID uint64, report DecommissionReport) ([]byte, error) {
+	// The contract closes the app account to the admin, or pays the admin when crowns are left, and when there is a king,
+	// pays the compensation first. The variant priced in an ASA closes its asset holding too.
+	accounts := []string{}
+	foreignAssets := []uint64{}
+	innerTxs := uint64(1)
+
+	if report.King != "" {
+		accounts = append(accounts, report.King)
+		innerTxs++
+	}
+
+	if report.AssetID != 0 {
+		foreignAssets = append(foreignAssets, report.AssetID)
+		innerTxs++
+	}
+
+	suggestedParams.FlatFee = true
+	suggestedParams.Fee = transaction.MinTxnFee * types.MicroAlgos(1+innerTxs)
+
+	tx, err := transaction.MakeApplicationDeleteTx(
+		appID,
+		nil,
+		accounts,
+		nil,
+		foreignAssets,
+		suggestedParams,
+		admin.Address,
+		[]byte(fmt.Sprintf(noteFormat, "decommission")),
+		types.Digest{},
+		[32]byte{},
+		types.ZeroAddress,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	signedBytes, _, err := signTransactions(ctx, admin, []types.Transaction{tx})
+	if err != nil {
+		return nil, err
+	}
+
+	return signedBytes, nil
+}
@@ -2,8 +2,7 @@ package client
 
 import (
 	"context"
-	"encoding/binary"
-	"path/filepath"
+	"encoding/base64"
 	"time"
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
@@ -11,87 +10,236 @@ import (
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func Deploy(ctx context.Context, algodClient *algod.Client, account crypto.Account, reignPeriod time.Duration, creationNote string) (uint64, error) {
-	globalInts := 6  // The prices, timestamp, period, admin fee and reward multiplier.
-	globalBytes := 2 // current king address and admin address
-	localInts := 0
-	localBytes := 0
+// MinBalance is the minimum balance of an account without assets or apps, like the app account.
+const MinBalance = 100000
 
-	gSchema := types.StateSchema{NumUint: uint64(globalInts), NumByteSlice: uint64(globalBytes)}
-	lSchema := types.StateSchema{NumUint: uint64(localInts), NumByteSlice: uint64(localBytes)}
+// programPageSize is the size of a page of the approval and clear programs, an app has up to 4 pages.
+const programPageSize = 2048
 
-	approvalProgram, err := openFile(filepath.Join("..", "contracts", "approval.teal"))
-	if err != nil {
-		return 0, err
-	}
+// DefaultUpgradeDelay is the delay between the announce of an upgrade or delete and its execution, the default of the app spec.
+const DefaultUpgradeDelay = time.Duration(KingOfAlgoDefaultUpgradeDelay) * time.Second
 
-	compiledApprovalProgram, err := compileProgram(ctx, algodClient, approvalProgram)
-	if err != nil {
-		return 0, err
-	}
+// Deploy creates the app and records it in the registry under the given name, a nil registry skips the record.
+// The upgrade delay is how long the players have to see an announced upgrade or delete before it runs, it can't change.
+func Deploy(
+	ctx context.Context,
+	algodClient *algod.Client,
+	account Account,
+	registry *Registry,
+	name string,
+	reignPeriod time.Duration,
+	upgradeDelay time.Duration,
+	creationNote string,
+) (appID uint64, err error) {
+	ctx, span := startSpan(ctx, "Deploy", senderAttr(account.Address), attribute.String("name", name))
+	defer func() { endSpan(span, err) }()
 
-	clearProgram, err := openFile(filepath.Join("..", "contracts", "clear.teal"))
+	compiledApprovalProgram, compiledClearProgram, err := compilePrograms(ctx, algodClient, contracts.Version)
 	if err != nil {
 		return 0, err
 	}
 
-	compiledClearProgram, err := compileProgram(ctx, algodClient, clearProgram)
+	gSchema, lSchema := KingOfAlgoStateSchemas()
+
+	createArgs, err := KingOfAlgoCreateArgs(uint64(reignPeriod.Seconds()), uint64(upgradeDelay.Seconds()))
 	if err != nil {
 		return 0, err
 	}
 
-	// Parse unix timestamp to bytes.
-	reignPeriodArg := make([]byte, 8)
-	binary.BigEndian.PutUint64(reignPeriodArg, uint64(reignPeriod.Seconds()))
+	return deployApp(ctx, algodClient, account, registry, appDeployment{
+		name:            name,
+		reignPeriod:     reignPeriod,
+		creationNote:    creationNote,
+		approvalProgram: compiledApprovalProgram,
+		clearProgram:    compiledClearProgram,
+		globalSchema:    gSchema,
+		localSchema:     lSchema,
+		args:            createArgs,
+		// Send minimum balance to app account 100000 0.1 ALGO.
+		// If we don't do this then the init payment to this address has to be > 0.1 ALGO. Which limits the init king's price.
+		initBalance: MinBalance,
+	})
+}
+
+// appDeployment is what differs between the variants of the contract when deploying.
+type appDeployment struct {
+	name            string
+	reignPeriod     time.Duration
+	creationNote    string
+	assetID         uint64
+	approvalProgram []byte
+	clearProgram    []byte
+	globalSchema    types.StateSchema
+	localSchema     types.StateSchema
+	args            [][]byte
+	initBalance     uint64
+}
 
+func deployApp(ctx context.Context, algodClient *algod.Client, account Account, registry *Registry, d appDeployment) (uint64, error) {
 	waitRounds := uint64(5)
 	suggestedParams, err := algodClient.SuggestedParams().Do(context.Background())
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
 
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return 0, err
+	}
+
+	// Fail before creating the app rather than after, an app without a name isn't registered.
+	if registry != nil && d.name != "" {
+		_, err = registry.Lookup(base64.StdEncoding.EncodeToString(suggestedParams.GenesisHash), d.name)
+		if err == nil {
+			return 0, errors.Errorf("app %q is already registered on %s", d.name, suggestedParams.GenesisID)
+		}
+	}
+
+	// The creation is only valid for the rounds waited, so the journal can tell when it expired.
+	suggestedParams.LastRoundValid = suggestedParams.FirstRoundValid + types.Round(waitRounds)
+
 	signedBytes, err := makeCreateAppTx(
 		ctx,
 		algodClient,
 		suggestedParams,
 		account,
-		compiledApprovalProgram,
-		compiledClearProgram,
-		gSchema,
-		lSchema,
-		[][]byte{reignPeriodArg},
-		[]byte(creationNote),
+		d.approvalProgram,
+		d.clearProgram,
+		d.globalSchema,
+		d.localSchema,
+		d.args,
+		[]byte(d.creationNote),
 	)
 	if err != nil {
 		return 0, err
 	}
 
-	resp, err := sendWaitTransaction(ctx, algodClient, signedBytes, waitRounds)
+	group, err := decodeSignedGroup(signedBytes)
 	if err != nil {
 		return 0, err
 	}
 
-	appID := resp.ApplicationIndex
+	entry := JournalEntry{
+		Kind:    JournalDeploy,
+		GroupID: groupID(group),
+		Name:    d.name,
+		Deploy: &DeployIntent{
+			Account:     account.Name,
+			Version:     contracts.Version,
+			ReignPeriod: d.reignPeriod,
+			AssetID:     d.assetID,
+			InitBalance: d.initBalance,
+			Note:        d.creationNote,
+		},
+	}
 
-	// Send minimum balance to app account 100000 0.1 ALGO.
-	// If we don't do this then the init payment to this address has to be > 0.1 ALGO. Which limits the init king's price.
-	err = sendInitBalance(ctx, algodClient, account, crypto.GetApplicationAddress(appID), 100000, waitRounds)
+	resp, err := submitGroup(ctx, algodClient, entry, signedBytes, 0, DefaultSubmitOptions)
 	if err != nil {
 		return 0, err
 	}
 
-	saveAppID(appID)
+	appID := resp.ApplicationIndex
+	trace.SpanFromContext(ctx).SetAttributes(appIDAttr(appID), roundAttr(resp.ConfirmedRound))
+	loggerFrom(ctx).InfoContext(ctx, "app created", "app_id", appID, "round", resp.ConfirmedRound, "sender", account.Address.String(), "name", d.name)
+
+	entry.AppID = appID
+	entry.Round = resp.ConfirmedRound
+	entry.Time = time.Now().UTC()
 
-	return appID, nil
+	return appID, finishDeploy(ctx, algodClient, account, registry, entry)
+}
+
+// CompleteDeploy finishes a deploy the journal recorded confirmed but not done, because the process stopped
+// after the creation of the app: it funds the app account, opts it in to its asset and registers it.
+// The account is the one of the deploy, named in its intent.
+func CompleteDeploy(ctx context.Context, algodClient *algod.Client, account Account, registry *Registry, entry JournalEntry) error {
+	if entry.Kind != JournalDeploy || entry.Deploy == nil {
+		return errors.Errorf("group %s isn't a deploy", entry.GroupID)
+	}
+
+	if entry.Status != JournalConfirmed {
+		return errors.Errorf("deploy %q is %s, not confirmed", entry.Name, entry.Status)
+	}
+
+	if account.Address.String() != entry.Sender {
+		return errors.Errorf("deploy %q was sent by %s, not by account %s", entry.Name, entry.Sender, account.Name)
+	}
+
+	return finishDeploy(ctx, algodClient, account, registry, entry)
+}
+
+// finishDeploy does what follows the creation of the app, skipping what is already done,
+// and records the deploy done in the journal of the context.
+func finishDeploy(ctx context.Context, algodClient *algod.Client, account Account, registry *Registry, entry JournalEntry) error {
+	waitRounds := uint64(5)
+	intent := entry.Deploy
+	appAddress := crypto.GetApplicationAddress(entry.AppID)
+
+	info, err := algodClient.AccountInformation(appAddress.String()).Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if info.Amount < intent.InitBalance {
+		err = sendInitBalance(ctx, algodClient, account, appAddress, intent.InitBalance-info.Amount, waitRounds)
+		if err != nil {
+			return err
+		}
+	}
+
+	if intent.AssetID != 0 {
+		_, err = assetHolding(ctx, algodClient, appAddress.String(), intent.AssetID)
+		if errors.Is(err, ErrNotOptedIn) {
+			err = optInAppToAsset(ctx, algodClient, account, entry.AppID, intent.AssetID, waitRounds)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if registry != nil && entry.Name != "" {
+		suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		_, err = registry.Lookup(base64.StdEncoding.EncodeToString(suggestedParams.GenesisHash), entry.Name)
+		if errors.Is(err, ErrAppNotFound) {
+			err = registry.Add(suggestedParams.GenesisID, suggestedParams.GenesisHash, RegistryEntry{
+				Name:         entry.Name,
+				AppID:        entry.AppID,
+				Version:      intent.Version,
+				Creator:      account.Address.String(),
+				CreatedRound: entry.Round,
+				CreatedAt:    entry.Time,
+				ReignPeriod:  intent.ReignPeriod,
+				AssetID:      intent.AssetID,
+				Note:         intent.Note,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	journal := journalFrom(ctx)
+	if journal == nil {
+		return nil
+	}
+
+	return journal.markDone(entry.GroupID)
 }
 
 func makeCreateAppTx(
-	_ context.Context,
+	ctx context.Context,
 	_ *algod.Client,
 	suggestedParams types.SuggestedParams,
-	sender crypto.Account,
+	sender Account,
 	approvalProgram []byte,
 	clearProgram []byte,
 	globalSchema types.StateSchema,
@@ -99,7 +247,7 @@ func makeCreateAppTx(
 	appArgs [][]byte,
 	note []byte,
 ) ([]byte, error) {
-	tx, err := transaction.MakeApplicationCreateTx(
+	tx, err := transaction.MakeApplicationCreateTxWithExtraPages(
 		false, // no-op
 		approvalProgram,
 		clearProgram,
@@ -115,33 +263,44 @@ func makeCreateAppTx(
 		types.Digest{},
 		[32]byte{},
 		types.Address{},
+		extraProgramPages(approvalProgram, clearProgram),
 	)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	_, signedBytes, err := crypto.SignTransaction(sender.PrivateKey, tx)
+	signedBytes, _, err := signTransactions(ctx, sender, []types.Transaction{tx})
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
 
 	return signedBytes, nil
 }
 
-func sendInitBalance(ctx context.Context, client *algod.Client, sender crypto.Account, receiver types.Address, amount uint64, waitRounds uint64) error {
+// extraProgramPages is the number of pages the programs need above the first one.
+func extraProgramPages(approvalProgram []byte, clearProgram []byte) uint32 {
+	return uint32((len(approvalProgram) + len(clearProgram) - 1) / programPageSize)
+}
+
+func sendInitBalance(ctx context.Context, client *algod.Client, sender Account, receiver types.Address, amount uint64, waitRounds uint64) error {
 	suggestedParams, err := client.SuggestedParams().Do(context.Background())
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	err = checkNetwork(ctx, client, suggestedParams)
+	if err != nil {
+		return err
+	}
+
 	tx, err := transaction.MakePaymentTxn(sender.Address.String(), receiver.String(), amount, nil, "", suggestedParams)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	_, signedBytes, err := crypto.SignTransaction(sender.PrivateKey, tx)
+	signedBytes, _, err := signTransactions(ctx, sender, []types.Transaction{tx})
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 
 	_, err = sendWaitTransaction(ctx, client, signedBytes, waitRounds)
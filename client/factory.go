@@ -0,0 +1,674 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+// MaxThrones is the max number of apps a factory holds at once, its global state can't hold more.
+const MaxThrones = 58
+
+// Min balance an account holds per created app and per global state entry.
+const (
+	appMinBalance     = 100000
+	uintEntryBalance  = 28500
+	bytesEntryBalance = 50000
+)
+
+// Global state keys of the factory, the other keys are the app IDs of the children.
+const (
+	factoryStateAdmin        = "admin"
+	factoryStateHash         = "child_hash"
+	factoryStateCount        = "count"
+	factoryStateUpgradeDelay = "upgrade_delay"
+	factoryStateUpgradeHash  = "upgrade_hash"
+	factoryStateUpgradeAt    = "upgrade_at"
+)
+
+const factoryWaitRounds = 5
+
+// Throne is an app of a factory.
+type Throne struct {
+	AppID     uint64
+	CreatedAt time.Time
+	State     State
+	Err       error
+}
+
+// factorySchemas returns the global and local schemas of the factory, one uint per child.
+func factorySchemas() (types.StateSchema, types.StateSchema) {
+	// The count, the upgrade delay and time, and the children; the admin, the child hash and the upgrade hash.
+	gSchema := types.StateSchema{NumUint: 3 + MaxThrones, NumByteSlice: 3}
+	lSchema := types.StateSchema{}
+
+	return gSchema, lSchema
+}
+
+// programsHash is the hash of the compiled programs, the factory accepts it for its children and the apps for their upgrades.
+func programsHash(approvalProgram []byte, clearProgram []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte{}, approvalProgram...), clearProgram...))
+
+	return hash[:]
+}
+
+// childFunding is what the factory needs for a new child: the child account and what it holds for the child app.
+func childFunding(schema types.StateSchema, extraPages uint32) uint64 {
+	return MinBalance + appMinBalance*(1+uint64(extraPages)) + schema.NumUint*uintEntryBalance + schema.NumByteSlice*bytesEntryBalance
+}
+
+// DeployFactory creates a factory whose children run the given contract version.
+// Like its children, the factory can only be updated to announced programs once the upgrade delay passed.
+func DeployFactory(ctx context.Context, algodClient *algod.Client, account Account, version string, upgradeDelay time.Duration) (uint64, error) {
+	approvalProgram, clearProgram, err := compileFactoryPrograms(ctx, algodClient, version)
+	if err != nil {
+		return 0, err
+	}
+
+	childApprovalProgram, childClearProgram, err := compilePrograms(ctx, algodClient, version)
+	if err != nil {
+		return 0, err
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return 0, err
+	}
+
+	gSchema, lSchema := factorySchemas()
+	signedBytes, err := makeCreateAppTx(
+		ctx,
+		algodClient,
+		suggestedParams,
+		account,
+		approvalProgram,
+		clearProgram,
+		gSchema,
+		lSchema,
+		[][]byte{programsHash(childApprovalProgram, childClearProgram), uint64Arg(uint64(upgradeDelay.Seconds()))},
+		[]byte(fmt.Sprintf(noteFormat, "factory")),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := sendWaitTransaction(ctx, algodClient, signedBytes, factoryWaitRounds)
+	if err != nil {
+		return 0, err
+	}
+
+	factoryID := resp.ApplicationIndex
+
+	err = sendInitBalance(ctx, algodClient, account, crypto.GetApplicationAddress(factoryID), MinBalance, factoryWaitRounds)
+	if err != nil {
+		return 0, err
+	}
+
+	return factoryID, nil
+}
+
+// CreateThrone creates an app with the given reign period and upgrade delay through the factory, the admin pays for its min balances.
+func CreateThrone(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, reignPeriod time.Duration, upgradeDelay time.Duration) (uint64, error) {
+	approvalProgram, clearProgram, err := compilePrograms(ctx, algodClient, contracts.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return 0, err
+	}
+
+	gSchema, _ := KingOfAlgoStateSchemas()
+	fundingTx, err := transaction.MakePaymentTxn(
+		admin.Address.String(),
+		crypto.GetApplicationAddress(factoryID).String(),
+		childFunding(gSchema, extraProgramPages(approvalProgram, clearProgram)),
+		[]byte(fmt.Sprintf(noteFormat, "throne_funding")),
+		"",
+		suggestedParams,
+	)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	// The factory creates and funds the child with two inner txs.
+	appParams := suggestedParams
+	appParams.FlatFee = true
+	appParams.Fee = transaction.MinTxnFee * 3
+
+	// The args of a tx hold up to a page, the tail of the approval program goes in the call after the create.
+	createArgs := [][]byte{
+		[]byte("create"),
+		nil,
+		clearProgram,
+		uint64Arg(uint64(reignPeriod.Seconds())),
+		uint64Arg(gSchema.NumUint),
+		uint64Arg(gSchema.NumByteSlice),
+		uint64Arg(uint64(upgradeDelay.Seconds())),
+	}
+
+	headSize := programPageSize
+	for _, arg := range createArgs {
+		headSize -= len(arg)
+	}
+
+	if headSize > len(approvalProgram) {
+		headSize = len(approvalProgram)
+	}
+
+	createArgs[1] = approvalProgram[:headSize]
+
+	createTx, err := transaction.MakeApplicationNoOpTx(
+		factoryID,
+		createArgs,
+		nil,
+		nil,
+		nil,
+		appParams,
+		admin.Address,
+		[]byte(fmt.Sprintf(noteFormat, "throne_create")),
+		types.Digest{},
+		[32]byte{},
+		types.ZeroAddress,
+	)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	programParams := suggestedParams
+	programParams.FlatFee = true
+	programParams.Fee = transaction.MinTxnFee
+
+	programTx, err := transaction.MakeApplicationNoOpTx(
+		factoryID,
+		[][]byte{[]byte("program"), approvalProgram[headSize:]},
+		nil,
+		nil,
+		nil,
+		programParams,
+		admin.Address,
+		[]byte(fmt.Sprintf(noteFormat, "throne_program")),
+		types.Digest{},
+		[32]byte{},
+		types.ZeroAddress,
+	)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	groupedTxs, err := transaction.AssignGroupID([]types.Transaction{fundingTx, createTx, programTx}, "")
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	signedBytes, _, err := signTransactions(ctx, admin, groupedTxs)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = sendWaitTransaction(ctx, algodClient, signedBytes, factoryWaitRounds)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, _, err := algodClient.PendingTransactionInformation(crypto.GetTxID(groupedTxs[1])).Do(ctx)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	if len(resp.InnerTxns) == 0 || resp.InnerTxns[0].ApplicationIndex == 0 {
+		return 0, errors.Errorf("factory %d didn't create an app", factoryID)
+	}
+
+	return resp.InnerTxns[0].ApplicationIndex, nil
+}
+
+// RetireThrone deletes an app of the factory, like Decommission the king gets its compensation first and the crowns
+// the app account holds are burned. The factory forwards what the app account held, with the admin fees it collected, to the admin.
+func RetireThrone(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64, force bool) (DecommissionReport, error) {
+	report, err := InspectDecommission(ctx, algodClient, appID)
+	if err != nil {
+		return DecommissionReport{}, err
+	}
+
+	if report.Admin != crypto.GetApplicationAddress(factoryID).String() {
+		return report, errors.Errorf("app %d doesn't belong to factory %d", appID, factoryID)
+	}
+
+	if report.IsReignActive() && !force {
+		return report, errors.WithStack(ErrReignActive)
+	}
+
+	err = checkDeleteAnnounced(report.Upgrade, appID)
+	if err != nil {
+		return report, err
+	}
+
+	// The app account can only be closed once the crowns it holds are burned.
+	if len(report.Uncollected) > 0 {
+		_, err = BurnThroneCrowns(ctx, algodClient, admin, factoryID, appID)
+		if err != nil {
+			return report, err
+		}
+
+		report, err = InspectDecommission(ctx, algodClient, appID)
+		if err != nil {
+			return DecommissionReport{}, err
+		}
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return report, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return report, err
+	}
+
+	// The delete, the close of the app account, or its payment to the factory when crowns are left, and the payment
+	// to the admin, plus the compensation when there is a king.
+	accounts := []string{}
+	innerTxs := uint64(3)
+
+	if report.King != "" {
+		accounts = append(accounts, report.King)
+		innerTxs++
+	}
+
+	suggestedParams.FlatFee = true
+	suggestedParams.Fee = transaction.MinTxnFee * types.MicroAlgos(1+innerTxs)
+
+	err = callFactory(ctx, algodClient, suggestedParams, admin, factoryID, "retire", nil, accounts, []uint64{appID}, nil)
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// AnnounceRetire announces the delete of an app of the factory, RetireThrone can run once the upgrade delay of the app passed.
+func AnnounceRetire(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64) (Upgrade, error) {
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return Upgrade{}, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	// The factory is the admin of the app, it announces with an inner call.
+	suggestedParams.FlatFee = true
+	suggestedParams.Fee = transaction.MinTxnFee * 2
+
+	err = callFactory(ctx, algodClient, suggestedParams, admin, factoryID, "announce_retire", nil, nil, []uint64{appID}, nil)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	if state.PendingUpgrade == nil {
+		return Upgrade{}, errors.Wrapf(ErrNoUpgrade, "app %d", appID)
+	}
+
+	return *state.PendingUpgrade, nil
+}
+
+// WithdrawFactory sends the admin fees the factory collected to the admin.
+func WithdrawFactory(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64) error {
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return err
+	}
+
+	suggestedParams.FlatFee = true
+	suggestedParams.Fee = transaction.MinTxnFee * 2
+
+	return callFactory(ctx, algodClient, suggestedParams, admin, factoryID, "withdraw", nil, nil, nil, nil)
+}
+
+// BurnThroneCrowns destroys the crowns an app of the factory still holds once its retire can run,
+// their min balance goes to the admin. It returns the crowns burned.
+func BurnThroneCrowns(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64) ([]uint64, error) {
+	state, err := factoryThrone(ctx, algodClient, factoryID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDeleteAnnounced(state.PendingUpgrade, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	crownIDs, err := UncollectedCrowns(ctx, algodClient, appID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, crownID := range crownIDs {
+		suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+		if err != nil {
+			return crownIDs[:i], errors.WithStack(err)
+		}
+
+		err = checkNetwork(ctx, algodClient, suggestedParams)
+		if err != nil {
+			return crownIDs[:i], err
+		}
+
+		// The call of the app, its destroy of the crown and payment to the factory, and the payment to the admin.
+		suggestedParams.FlatFee = true
+		suggestedParams.Fee = transaction.MinTxnFee * 5
+
+		err = callFactory(ctx, algodClient, suggestedParams, admin, factoryID, "burn_crown", [][]byte{uint64Arg(crownID)}, nil, []uint64{appID}, []uint64{crownID})
+		if err != nil {
+			return crownIDs[:i], errors.Wrapf(err, "burn crown %d", crownID)
+		}
+	}
+
+	return crownIDs, nil
+}
+
+// PauseThrone stops the claims of an app of the factory until UnpauseThrone.
+func PauseThrone(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64) error {
+	return callThrone(ctx, algodClient, admin, factoryID, appID, "pause", nil)
+}
+
+// UnpauseThrone lets the players claim an app of the factory again.
+func UnpauseThrone(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64) error {
+	return callThrone(ctx, algodClient, admin, factoryID, appID, "unpause", nil)
+}
+
+// SetThroneParams sets the params of the next reign of an app of the factory.
+func SetThroneParams(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64, params Params) error {
+	err := params.Validate()
+	if err != nil {
+		return err
+	}
+
+	return callThrone(ctx, algodClient, admin, factoryID, appID, "set_params", [][]byte{
+		uint64Arg(params.AdminFee),
+		uint64Arg(params.RewardMultiplier),
+		uint64Arg(uint64(params.ReignPeriod.Seconds())),
+	})
+}
+
+// HandOverThrone proposes the new admin to an app of the factory and removes the app from the factory,
+// the new admin runs it on its own once it accepts with AcceptAdmin.
+func HandOverThrone(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64, newAdmin string) error {
+	address, err := types.DecodeAddress(newAdmin)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return callThrone(ctx, algodClient, admin, factoryID, appID, "hand_over", [][]byte{address[:]})
+}
+
+// GetFactoryUpgrade returns the announced upgrade of the factory, nil when there is none.
+func GetFactoryUpgrade(ctx context.Context, algodClient *algod.Client, factoryID uint64) (*Upgrade, error) {
+	factory, err := algodClient.GetApplicationByID(factoryID).Do(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var hash []byte
+	var at uint64
+	for _, keyValue := range factory.Params.GlobalState {
+		key, err := base64.StdEncoding.DecodeString(keyValue.Key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		switch string(key) {
+		case factoryStateUpgradeHash:
+			hash, err = base64.StdEncoding.DecodeString(keyValue.Value.Bytes)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		case factoryStateUpgradeAt:
+			at = keyValue.Value.Uint
+		}
+	}
+
+	if hash == nil {
+		return nil, nil
+	}
+
+	return &Upgrade{Hash: hash, At: time.Unix(int64(at), 0)}, nil
+}
+
+// AnnounceFactoryUpgrade announces the compiled programs the factory will run, the admin can update the factory to them
+// with ExecuteFactoryUpgrade once the upgrade delay of the factory passed. Announcing again restarts the delay.
+func AnnounceFactoryUpgrade(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, approvalProgram []byte, clearProgram []byte) (Upgrade, error) {
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return Upgrade{}, errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	args := [][]byte{programsHash(approvalProgram, clearProgram)}
+	err = callFactory(ctx, algodClient, suggestedParams, admin, factoryID, "announce_upgrade", args, nil, nil, nil)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	// The factory sets the time from the block, read it back rather than guessing it.
+	upgrade, err := GetFactoryUpgrade(ctx, algodClient, factoryID)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	if upgrade == nil {
+		return Upgrade{}, errors.Wrapf(ErrNoUpgrade, "factory %d", factoryID)
+	}
+
+	return *upgrade, nil
+}
+
+// CancelFactoryUpgrade removes the announced upgrade of the factory.
+func CancelFactoryUpgrade(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64) error {
+	upgrade, err := GetFactoryUpgrade(ctx, algodClient, factoryID)
+	if err != nil {
+		return err
+	}
+
+	if upgrade == nil {
+		return errors.Wrapf(ErrNoUpgrade, "factory %d", factoryID)
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return err
+	}
+
+	return callFactory(ctx, algodClient, suggestedParams, admin, factoryID, "cancel_upgrade", nil, nil, nil, nil)
+}
+
+// ExecuteFactoryUpgrade updates the factory to the announced programs once its upgrade delay passed.
+func ExecuteFactoryUpgrade(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, approvalProgram []byte, clearProgram []byte) error {
+	upgrade, err := GetFactoryUpgrade(ctx, algodClient, factoryID)
+	if err != nil {
+		return err
+	}
+
+	if upgrade == nil {
+		return errors.Wrapf(ErrNoUpgrade, "factory %d", factoryID)
+	}
+
+	if !bytes.Equal(upgrade.Hash, programsHash(approvalProgram, clearProgram)) {
+		return errors.Errorf("the programs don't match the upgrade announced for factory %d", factoryID)
+	}
+
+	if !upgrade.Ready() {
+		return errors.Wrapf(ErrTimelocked, "factory %d until %s", factoryID, upgrade.At)
+	}
+
+	return updateApp(ctx, algodClient, admin, factoryID, approvalProgram, clearProgram, factoryWaitRounds)
+}
+
+// factoryThrone returns the state of an app of the factory, it fails when the factory isn't the admin of the app.
+func factoryThrone(ctx context.Context, algodClient *algod.Client, factoryID uint64, appID uint64) (State, error) {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return State{}, err
+	}
+
+	if state.Admin != crypto.GetApplicationAddress(factoryID).String() {
+		return State{}, errors.Errorf("app %d doesn't belong to factory %d", appID, factoryID)
+	}
+
+	return state, nil
+}
+
+// callThrone calls an admin method of an app of the factory, the factory calls the app with an inner tx.
+func callThrone(ctx context.Context, algodClient *algod.Client, admin Account, factoryID uint64, appID uint64, method string, args [][]byte) error {
+	_, err := factoryThrone(ctx, algodClient, factoryID, appID)
+	if err != nil {
+		return err
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return err
+	}
+
+	suggestedParams.FlatFee = true
+	suggestedParams.Fee = transaction.MinTxnFee * 2
+
+	return callFactory(ctx, algodClient, suggestedParams, admin, factoryID, method, args, nil, []uint64{appID}, nil)
+}
+
+func callFactory(
+	ctx context.Context,
+	algodClient *algod.Client,
+	suggestedParams types.SuggestedParams,
+	admin Account,
+	factoryID uint64,
+	method string,
+	args [][]byte,
+	accounts []string,
+	foreignApps []uint64,
+	foreignAssets []uint64,
+) error {
+	tx, err := transaction.MakeApplicationNoOpTx(
+		factoryID,
+		append([][]byte{[]byte(method)}, args...),
+		accounts,
+		foreignApps,
+		foreignAssets,
+		suggestedParams,
+		admin.Address,
+		[]byte(fmt.Sprintf(noteFormat, "throne_"+method)),
+		types.Digest{},
+		[32]byte{},
+		types.ZeroAddress,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	signedBytes, _, err := signTransactions(ctx, admin, []types.Transaction{tx})
+	if err != nil {
+		return err
+	}
+
+	_, err = sendWaitTransaction(ctx, algodClient, signedBytes, factoryWaitRounds)
+
+	return err
+}
+
+// ListThrones returns the apps of the factory with their state, sorted by app ID.
+// An app whose state can't be read has its error set.
+func ListThrones(ctx context.Context, algodClient *algod.Client, factoryID uint64, opts StatesOptions) ([]Throne, error) {
+	factory, err := algodClient.GetApplicationByID(factoryID).Do(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	thrones := []Throne{}
+	for _, keyValue := range factory.Params.GlobalState {
+		key, err := base64.StdEncoding.DecodeString(keyValue.Key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		switch string(key) {
+		case factoryStateAdmin, factoryStateHash, factoryStateCount, factoryStateUpgradeDelay, factoryStateUpgradeHash, factoryStateUpgradeAt:
+			continue
+		}
+
+		if len(key) != 8 {
+			return nil, errors.Errorf("unexpected factory state key %q", key)
+		}
+
+		thrones = append(thrones, Throne{
+			AppID:     binary.BigEndian.Uint64(key),
+			CreatedAt: time.Unix(int64(keyValue.Value.Uint), 0),
+		})
+	}
+
+	sort.Slice(thrones, func(i, j int) bool {
+		return thrones[i].AppID < thrones[j].AppID
+	})
+
+	appIDs := make([]uint64, len(thrones))
+	for i, throne := range thrones {
+		appIDs[i] = throne.AppID
+	}
+
+	for i, appState := range GetContractStates(ctx, algodClient, appIDs, opts) {
+		thrones[i].State = appState.State
+		thrones[i].Err = appState.Err
+	}
+
+	return thrones, nil
+}
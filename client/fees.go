@@ -0,0 +1,147 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// ErrFeeCap is returned when the fees a strategy picks for a group are above its max fee.
+var ErrFeeCap = errors.New("the fees are above the max fee")
+
+// DefaultMaxFee caps the fees of a claim group when the strategy doesn't set a max fee, 0.02 ALGO.
+const DefaultMaxFee = 20 * transaction.MinTxnFee
+
+// DefaultFeeMultiplier is the multiplier of the congestion mode when the strategy doesn't set one.
+const DefaultFeeMultiplier = 2
+
+// FeeMode is how a strategy picks the fee of every tx of a group.
+type FeeMode string
+
+const (
+	// FeeMinimum pays the min fee per tx.
+	FeeMinimum FeeMode = "minimum"
+	// FeeSuggested pays the fee per byte suggested by the node, the min fee when the network isn't congested.
+	FeeSuggested FeeMode = "suggested"
+	// FeeCongestion pays the suggested fee times the multiplier when the network is congested, the min fee otherwise.
+	FeeCongestion FeeMode = "congestion"
+	// FeePooled pays the fees of the whole group with the app call, the other txs pay nothing.
+	FeePooled FeeMode = "pooled"
+)
+
+// FeeStrategy picks the fees of the txs of a group, the app call always covers the fees of its inner txs.
+type FeeStrategy struct {
+	Mode FeeMode `default:"minimum"`
+	// Multiplier of the suggested fee in the congestion mode, DefaultFeeMultiplier when zero.
+	Multiplier uint64
+	// MaxFee caps the fees of the whole group in microalgos, inner txs included, DefaultMaxFee when zero.
+	MaxFee uint64
+}
+
+// Fees are the fees picked for a group.
+type Fees struct {
+	Mode FeeMode
+	// Txs are the fees of the txs of the group in the group order.
+	Txs []uint64
+	// InnerTxs is the number of inner txs the fee of the app call covers.
+	InnerTxs uint64
+	// Total is the sum of the fees of the group.
+	Total uint64
+}
+
+func (f Fees) String() string {
+	return fmt.Sprintf("%d microalgos (%s, %v, %d inner txs)", f.Total, f.Mode, f.Txs, f.InnerTxs)
+}
+
+func (s FeeStrategy) maxFee() uint64 {
+	if s.MaxFee == 0 {
+		return DefaultMaxFee
+	}
+
+	return s.MaxFee
+}
+
+func (s FeeStrategy) multiplier() uint64 {
+	if s.Multiplier == 0 {
+		return DefaultFeeMultiplier
+	}
+
+	return s.Multiplier
+}
+
+// apply sets the fees of the group, whose last tx is the app call with the inner txs, and assigns the group ID again.
+func (s FeeStrategy) apply(txs []types.Transaction, suggestedParams types.SuggestedParams, innerTxs uint64) ([]types.Transaction, Fees, error) {
+	minFee := suggestedParams.MinFee
+	if minFee == 0 {
+		minFee = transaction.MinTxnFee
+	}
+
+	// Without a flat fee the suggested fee is per byte, algod suggests zero when the network isn't congested.
+	feePerByte := uint64(0)
+	if !suggestedParams.FlatFee {
+		feePerByte = uint64(suggestedParams.Fee)
+	}
+
+	if s.Mode == "" {
+		s.Mode = FeeMinimum
+	}
+
+	fees := Fees{Mode: s.Mode, Txs: make([]uint64, len(txs)), InnerTxs: innerTxs}
+	for i := range txs {
+		// The size with the max fee, the fee picked can't take more bytes.
+		txs[i].Fee = types.MicroAlgos(s.maxFee())
+		txs[i].Group = types.Digest{}
+		size, err := transaction.EstimateSize(txs[i])
+		if err != nil {
+			return nil, Fees{}, errors.WithStack(err)
+		}
+
+		suggestedFee := feePerByte * size
+		if suggestedFee < minFee {
+			suggestedFee = minFee
+		}
+
+		switch s.Mode {
+		case FeeMinimum:
+			fees.Txs[i] = minFee
+		case FeeSuggested, FeePooled:
+			fees.Txs[i] = suggestedFee
+		case FeeCongestion:
+			fees.Txs[i] = minFee
+			if feePerByte > 0 {
+				fees.Txs[i] = suggestedFee * s.multiplier()
+			}
+		default:
+			return nil, Fees{}, errors.Errorf("unknown fee mode %q", s.Mode)
+		}
+	}
+
+	// The app call is last, its inner txs pay the min fee.
+	appCall := len(txs) - 1
+	fees.Txs[appCall] += innerTxs * minFee
+
+	if s.Mode == FeePooled {
+		for i := 0; i < appCall; i++ {
+			fees.Txs[appCall] += fees.Txs[i]
+			fees.Txs[i] = 0
+		}
+	}
+
+	for i, fee := range fees.Txs {
+		txs[i].Fee = types.MicroAlgos(fee)
+		fees.Total += fee
+	}
+
+	if fees.Total > s.maxFee() {
+		return nil, fees, errors.Wrapf(ErrFeeCap, "%s, max %d", fees, s.maxFee())
+	}
+
+	groupedTxs, err := transaction.AssignGroupID(txs, "")
+	if err != nil {
+		return nil, Fees{}, errors.WithStack(err)
+	}
+
+	return groupedTxs, fees, nil
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/indexer"
+	"github.com/pkg/errors"
+)
+
+// Actions of the global state deltas.
+const (
+	deltaSetBytes = 1
+	deltaSetUint  = 2
+	deltaDelete   = 3
+)
+
+// indexerPageSize is the number of transactions asked to the indexer per request.
+const indexerPageSize = 1000
+
+// StateDelta is a change of the global state made by a transaction.
+type StateDelta struct {
+	Round  uint64                     `json:"round"`
+	Time   time.Time                  `json:"time"`
+	TxID   string                     `json:"txId"`
+	Sender string                     `json:"sender"`
+	Delta  []models.EvalDeltaKeyValue `json:"delta"`
+}
+
+// StateChange is the state of the app right after a transaction changed it.
+type StateChange struct {
+	StateDelta
+	State State
+}
+
+// EventSource returns the global state deltas of an app from its creation, ordered by round.
+type EventSource interface {
+	StateDeltas(ctx context.Context, appID uint64, maxRound uint64) ([]StateDelta, error)
+}
+
+// StateAt rebuilds the state of the app as it was at the end of the given round.
+func StateAt(ctx context.Context, source EventSource, appID uint64, round uint64) (State, error) {
+	changes, err := StateHistory(ctx, source, appID, round)
+	if err != nil {
+		return State{}, err
+	}
+
+	if len(changes) == 0 {
+		return State{}, errors.Errorf("app %d didn't exist at round %d", appID, round)
+	}
+
+	return changes[len(changes)-1].State, nil
+}
+
+// StateHistory replays the deltas up to the given round, zero means up to now,
+// and returns the state after every change with the transaction that caused it.
+func StateHistory(ctx context.Context, source EventSource, appID uint64, maxRound uint64) ([]StateChange, error) {
+	deltas, err := source.StateDeltas(ctx, appID, maxRound)
+	if err != nil {
+		return nil, err
+	}
+
+	global := map[string]models.TealValue{}
+	changes := []StateChange{}
+	for _, delta := range deltas {
+		err = applyDelta(global, delta.Delta)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx %s", delta.TxID)
+		}
+
+		rawState := []models.TealKeyValue{}
+		for key, value := range global {
+			rawState = append(rawState, models.TealKeyValue{Key: key, Value: value})
+		}
+
+		state, err := FormatState(rawState)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, StateChange{StateDelta: delta, State: state})
+	}
+
+	return changes, nil
+}
+
+// StateChangesBetween returns the changes made in the time range.
+func StateChangesBetween(ctx context.Context, source EventSource, appID uint64, from time.Time, to time.Time) ([]StateChange, error) {
+	changes, err := StateHistory(ctx, source, appID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []StateChange{}
+	for _, change := range changes {
+		if change.Time.Before(from) || change.Time.After(to) {
+			continue
+		}
+
+		result = append(result, change)
+	}
+
+	return result, nil
+}
+
+// applyDelta applies the delta to the global state keyed by the base64 key.
+func applyDelta(global map[string]models.TealValue, delta []models.EvalDeltaKeyValue) error {
+	for _, keyValue := range delta {
+		switch keyValue.Value.Action {
+		case deltaSetBytes:
+			global[keyValue.Key] = models.TealValue{Type: tealBytesType, Bytes: keyValue.Value.Bytes}
+		case deltaSetUint:
+			global[keyValue.Key] = models.TealValue{Type: tealUintType, Uint: keyValue.Value.Uint}
+		case deltaDelete:
+			delete(global, keyValue.Key)
+		default:
+			return errors.Errorf("unknown delta action %d", keyValue.Value.Action)
+		}
+	}
+
+	return nil
+}
+
+// IndexerSource reads the deltas of the app calls from the indexer.
+type IndexerSource struct {
+	Indexer *indexer.Client
+}
+
+func (s IndexerSource) StateDeltas(ctx context.Context, appID uint64, maxRound uint64) ([]StateDelta, error) {
+	deltas := []StateDelta{}
+	nextToken := ""
+	for {
+		req := s.Indexer.SearchForTransactions().
+			ApplicationId(appID).
+			TxType("appl").
+			Limit(indexerPageSize).
+			NextToken(nextToken)
+		if maxRound > 0 {
+			req = req.MaxRound(maxRound)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, tx := range resp.Transactions {
+			deltas = appendAppDeltas(deltas, appID, tx, tx, tx.Id)
+		}
+
+		if resp.NextToken == "" || len(resp.Transactions) == 0 {
+			return deltas, nil
+		}
+
+		nextToken = resp.NextToken
+	}
+}
+
+// appendAppDeltas appends the deltas of the app made by the tx and its inner txs, in the order they ran.
+// The indexer returns the root tx of the inner calls, like the call of the factory creating the app,
+// whose own delta belongs to another app. The inner txs have no ID, theirs is the path from the root.
+func appendAppDeltas(deltas []StateDelta, appID uint64, root models.Transaction, tx models.Transaction, txID string) []StateDelta {
+	calledApp := tx.ApplicationTransaction.ApplicationId
+	if calledApp == 0 {
+		calledApp = tx.CreatedApplicationIndex
+	}
+
+	if tx.Type == "appl" && calledApp == appID && len(tx.GlobalStateDelta) > 0 {
+		deltas = append(deltas, StateDelta{
+			Round:  root.ConfirmedRound,
+			Time:   time.Unix(int64(root.RoundTime), 0).UTC(),
+			TxID:   txID,
+			Sender: tx.Sender,
+			Delta:  tx.GlobalStateDelta,
+		})
+	}
+
+	for i, inner := range tx.InnerTxns {
+		deltas = appendAppDeltas(deltas, appID, root, inner, fmt.Sprintf("%s/inner/%d", txID, i))
+	}
+
+	return deltas
+}
+
+// FileEventStore keeps the deltas of the apps in a local JSON lines file, so the history
+// can be read without an indexer. Fill it with Sync.
+type FileEventStore struct {
+	path string
+}
+
+type storedDelta struct {
+	AppID uint64 `json:"appId"`
+	StateDelta
+}
+
+// NewFileEventStore returns the event store of the file, it is created on the first append.
+func NewFileEventStore(path string) *FileEventStore {
+	return &FileEventStore{path: path}
+}
+
+func (s *FileEventStore) StateDeltas(_ context.Context, appID uint64, maxRound uint64) ([]StateDelta, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer f.Close()
+
+	deltas := []StateDelta{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		stored := storedDelta{}
+		err = json.Unmarshal(scanner.Bytes(), &stored)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid event store %s", s.path)
+		}
+
+		if stored.AppID != appID || (maxRound > 0 && stored.Round > maxRound) {
+			continue
+		}
+
+		deltas = append(deltas, stored.StateDelta)
+	}
+
+	if scanner.Err() != nil {
+		return nil, errors.WithStack(scanner.Err())
+	}
+
+	// Deltas of a round stay in the order they were appended.
+	sort.SliceStable(deltas, func(i, j int) bool {
+		return deltas[i].Round < deltas[j].Round
+	})
+
+	return deltas, nil
+}
+
+// Append adds the deltas of the app at the end of the file.
+func (s *FileEventStore) Append(appID uint64, deltas ...StateDelta) error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	enc := json.NewEncoder(f)
+	for _, delta := range deltas {
+		err = enc.Encode(storedDelta{AppID: appID, StateDelta: delta})
+		if err != nil {
+			f.Close()
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(f.Close())
+}
+
+// Sync copies to the store the deltas of the app it doesn't have yet.
+func (s *FileEventStore) Sync(ctx context.Context, source EventSource, appID uint64) error {
+	stored, err := s.StateDeltas(ctx, appID, 0)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, delta := range stored {
+		known[delta.TxID] = true
+	}
+
+	deltas, err := source.StateDeltas(ctx, appID, 0)
+	if err != nil {
+		return err
+	}
+
+	missing := []StateDelta{}
+	for _, delta := range deltas {
+		if !known[delta.TxID] {
+			missing = append(missing, delta)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return s.Append(appID, missing...)
+}
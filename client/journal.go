@@ -0,0 +1,418 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JournalStatus is where a journaled group stands.
+type JournalStatus string
+
+const (
+	// JournalPending is a group sent, or about to be, whose outcome isn't known yet.
+	JournalPending JournalStatus = "pending"
+	// JournalConfirmed is a group confirmed in a block.
+	JournalConfirmed JournalStatus = "confirmed"
+	// JournalExpired is a group whose validity window passed before it was confirmed, it can't be confirmed anymore.
+	JournalExpired JournalStatus = "expired"
+	// JournalRejected is a group the node refused or dropped from its pool.
+	JournalRejected JournalStatus = "rejected"
+)
+
+// Kinds of the journaled operations.
+const (
+	JournalClaim  = "claim"
+	JournalDeploy = "deploy"
+)
+
+// JournalEntry is a submitted group and the state it intends.
+type JournalEntry struct {
+	Kind string `json:"kind"`
+	// GroupID is the group ID in base64, the tx ID for a single tx.
+	GroupID string   `json:"groupId"`
+	TxIDs   []string `json:"txIds"`
+	Sender  string   `json:"sender"`
+	// AppID is the app claimed, or the app created once a deploy is confirmed.
+	AppID uint64 `json:"appId,omitempty"`
+	// Name is the name of a deployed app in the registry.
+	Name string `json:"name,omitempty"`
+	// Reign and Price are the reign a claim starts and the price it pays.
+	Reign      uint64        `json:"reign,omitempty"`
+	Price      uint64        `json:"price,omitempty"`
+	FirstValid uint64        `json:"firstValid"`
+	LastValid  uint64        `json:"lastValid"`
+	Status     JournalStatus `json:"status"`
+	Round      uint64        `json:"round,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Time       time.Time     `json:"time"`
+	// Deploy is what a deploy does once its app is created.
+	Deploy *DeployIntent `json:"deploy,omitempty"`
+	// Done is a confirmed deploy whose app is funded, opted in to its asset and registered.
+	Done bool `json:"done,omitempty"`
+}
+
+// DeployIntent is what a deploy does after the creation of its app, to finish it when the process stopped in between.
+type DeployIntent struct {
+	// Account is the name of the account creating the app in the config.
+	Account     string        `json:"account"`
+	Version     string        `json:"version"`
+	ReignPeriod time.Duration `json:"reignPeriod"`
+	AssetID     uint64        `json:"assetId,omitempty"`
+	InitBalance uint64        `json:"initBalance"`
+	Note        string        `json:"note,omitempty"`
+}
+
+// Journal is a write-ahead journal of the submitted groups in a local JSON lines file: a group is recorded before
+// it is sent and again with its outcome, so a process restarted in between can reconcile it.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJournal returns the journal of the file, it is created on the first append.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+type journalKey struct{}
+
+// WithJournal returns a context whose claims and deploys are recorded in the journal.
+func WithJournal(ctx context.Context, journal *Journal) context.Context {
+	return context.WithValue(ctx, journalKey{}, journal)
+}
+
+func journalFrom(ctx context.Context) *Journal {
+	journal, _ := ctx.Value(journalKey{}).(*Journal)
+
+	return journal
+}
+
+// Append records the entry at the end of the file and syncs it to the disk.
+func (j *Journal) Append(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = json.NewEncoder(f).Encode(entry)
+	if err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(f.Close())
+}
+
+// Entries returns the last record of every group, in the order they were first sent.
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer f.Close()
+
+	entries := []JournalEntry{}
+	index := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		entry := JournalEntry{}
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			// A crash while appending leaves the last line torn, the record before it stands.
+			continue
+		}
+
+		i, ok := index[entry.GroupID]
+		if !ok {
+			index[entry.GroupID] = len(entries)
+			entries = append(entries, entry)
+			continue
+		}
+
+		entries[i] = entry
+	}
+
+	if scanner.Err() != nil {
+		return nil, errors.WithStack(scanner.Err())
+	}
+
+	return entries, nil
+}
+
+// Pending returns the groups whose outcome isn't recorded.
+func (j *Journal) Pending() ([]JournalEntry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []JournalEntry{}
+	for _, entry := range entries {
+		if entry.Status == JournalPending {
+			pending = append(pending, entry)
+		}
+	}
+
+	return pending, nil
+}
+
+// Incomplete returns the confirmed deploys whose app isn't funded, opted in or registered yet,
+// finish them with CompleteDeploy.
+func (j *Journal) Incomplete() ([]JournalEntry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	incomplete := []JournalEntry{}
+	for _, entry := range entries {
+		if entry.Kind == JournalDeploy && entry.Status == JournalConfirmed && !entry.Done {
+			incomplete = append(incomplete, entry)
+		}
+	}
+
+	return incomplete, nil
+}
+
+// Reconcile asks the node what became of the pending groups and records their outcome: it waits for the groups
+// still within their validity window and looks for the expired ones in the blocks of their window.
+// A group it can't settle stays pending and doesn't stop the others, the error tells all of them.
+// The deploys it confirms are incomplete until CompleteDeploy finishes them.
+func (j *Journal) Reconcile(ctx context.Context, algodClient *algod.Client, opts SubmitOptions) ([]JournalEntry, error) {
+	pending, err := j.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	reconciled := []JournalEntry{}
+	problems := []string{}
+	for _, entry := range pending {
+		txID := entry.TxIDs[len(entry.TxIDs)-1]
+		info, err := waitTransaction(ctx, algodClient, txID, entry.LastValid, 0, opts)
+		if errors.Is(err, ErrExpired) {
+			info, err = findConfirmed(ctx, algodClient, txID, entry.FirstValid, entry.LastValid, opts)
+		}
+
+		entry = entry.withOutcome(info, err)
+		if entry.Status == JournalPending {
+			problems = append(problems, fmt.Sprintf("group %s: %s", entry.GroupID, err))
+			continue
+		}
+
+		err = j.Append(entry)
+		if err != nil {
+			return reconciled, err
+		}
+
+		reconciled = append(reconciled, entry)
+	}
+
+	if len(problems) > 0 {
+		return reconciled, errors.Errorf("%d of %d pending groups not reconciled: %s", len(problems), len(pending), strings.Join(problems, "; "))
+	}
+
+	return reconciled, nil
+}
+
+// markDone records the deploy of the group done.
+func (j *Journal) markDone(groupID string) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.GroupID == groupID {
+			entry.Done = true
+			entry.Time = time.Now().UTC()
+
+			return j.Append(entry)
+		}
+	}
+
+	return errors.Errorf("group %s isn't in the journal", groupID)
+}
+
+// withOutcome is the entry with the outcome of the wait for its group, still pending when the wait failed
+// for another reason than the group.
+func (e JournalEntry) withOutcome(info models.PendingTransactionInfoResponse, err error) JournalEntry {
+	e.Time = time.Now().UTC()
+	switch {
+	case err == nil:
+		e.Status = JournalConfirmed
+		e.Round = info.ConfirmedRound
+		if e.Kind == JournalDeploy {
+			e.AppID = info.ApplicationIndex
+		}
+	case errors.Is(err, ErrExpired):
+		e.Status = JournalExpired
+		e.Error = err.Error()
+	case errors.Is(err, ErrRejected), errors.Is(err, ErrClaimPending):
+		e.Status = JournalRejected
+		e.Error = err.Error()
+	}
+
+	return e
+}
+
+// findConfirmed looks for the tx in the blocks of its validity window, for the groups the node forgot,
+// and returns ErrExpired when it isn't there.
+func findConfirmed(ctx context.Context, algodClient *algod.Client, txID string, firstValid uint64, lastValid uint64, opts SubmitOptions) (models.PendingTransactionInfoResponse, error) {
+	for round := firstValid; round <= lastValid; round++ {
+		txIDs, err := retry(ctx, opts, func() (models.BlockTxidsResponse, error) {
+			return algodClient.GetBlockTxids(round).Do(ctx)
+		})
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, errors.Wrapf(err, "block %d", round)
+		}
+
+		for i, id := range txIDs.Blocktxids {
+			if id != txID {
+				continue
+			}
+
+			// The block tells the app a creation created.
+			block, err := retry(ctx, opts, func() (types.Block, error) {
+				return algodClient.Block(round).Do(ctx)
+			})
+			if err != nil {
+				return models.PendingTransactionInfoResponse{}, errors.Wrapf(err, "block %d", round)
+			}
+
+			return models.PendingTransactionInfoResponse{
+				ConfirmedRound:   round,
+				ApplicationIndex: block.Payset[i].ApplicationID,
+			}, nil
+		}
+	}
+
+	return models.PendingTransactionInfoResponse{}, errors.Wrapf(ErrExpired, "tx %s after round %d", txID, lastValid)
+}
+
+// decodeSignedGroup decodes the signed txs of a group sent as one.
+func decodeSignedGroup(rawTxn []byte) ([]types.SignedTxn, error) {
+	group := []types.SignedTxn{}
+	dec := msgpack.NewDecoder(bytes.NewReader(rawTxn))
+	for {
+		stx := types.SignedTxn{}
+		err := dec.Decode(&stx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		group = append(group, stx)
+	}
+
+	if len(group) == 0 {
+		return nil, errors.New("empty group")
+	}
+
+	return group, nil
+}
+
+// groupID is the group ID of the signed group in base64, the tx ID for a single tx.
+func groupID(group []types.SignedTxn) string {
+	last := group[len(group)-1].Txn
+	if last.Group == (types.Digest{}) {
+		return crypto.GetTxID(group[0].Txn)
+	}
+
+	return base64.StdEncoding.EncodeToString(last.Group[:])
+}
+
+// submitGroup sends the signed group and waits for its last tx like sendWaitGroup, recording it with the intent
+// in the journal of the context before sending and its outcome after.
+func submitGroup(
+	ctx context.Context,
+	algodClient *algod.Client,
+	intent JournalEntry,
+	rawTxn []byte,
+	waitRounds uint64,
+	opts SubmitOptions,
+) (info models.PendingTransactionInfoResponse, err error) {
+	ctx, span := startSpan(ctx, "submitGroup", attribute.String("kind", intent.Kind), appIDAttr(intent.AppID))
+	defer func() { endSpan(span, err) }()
+
+	group, err := decodeSignedGroup(rawTxn)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+
+	last := group[len(group)-1].Txn
+	entry := intent
+	entry.Sender = last.Sender.String()
+	entry.FirstValid = uint64(last.FirstValid)
+	entry.LastValid = uint64(last.LastValid)
+	entry.Status = JournalPending
+	entry.Time = time.Now().UTC()
+	for _, stx := range group {
+		entry.TxIDs = append(entry.TxIDs, crypto.GetTxID(stx.Txn))
+	}
+
+	entry.GroupID = groupID(group)
+
+	journal := journalFrom(ctx)
+	if journal != nil {
+		err = journal.Append(entry)
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+	}
+
+	err = sendGroup(ctx, algodClient, rawTxn, opts)
+	if err == nil {
+		info, err = waitTransaction(ctx, algodClient, entry.TxIDs[len(entry.TxIDs)-1], entry.LastValid, waitRounds, opts)
+	}
+
+	if journal == nil {
+		return info, err
+	}
+
+	// A group whose outcome isn't known stays pending for the reconciliation.
+	outcome := entry.withOutcome(info, err)
+	if outcome.Status != JournalPending {
+		appendErr := journal.Append(outcome)
+		if err == nil {
+			err = appendErr
+		}
+	}
+
+	return info, err
+}
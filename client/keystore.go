@@ -0,0 +1,285 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const keystoreVersion = 1
+
+// Default scrypt parameters, stored with every account so they can be raised later.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrAccountNotFound is returned when the keystore has no account with the requested name.
+var ErrAccountNotFound = errors.New("account not found in the keystore")
+
+// Keystore holds named accounts whose private keys are encrypted with a passphrase.
+type Keystore struct {
+	path     string
+	Version  int                        `json:"version"`
+	Accounts map[string]keystoreAccount `json:"accounts"`
+}
+
+type keystoreAccount struct {
+	Address string `json:"address"`
+	KDF     struct {
+		Salt []byte `json:"salt"`
+		N    int    `json:"n"`
+		R    int    `json:"r"`
+		P    int    `json:"p"`
+	} `json:"kdf"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// KeystoreEntry is the public part of an account in the keystore.
+type KeystoreEntry struct {
+	Name    string
+	Address string
+}
+
+// OpenKeystore reads the keystore file, a missing file is an empty keystore.
+func OpenKeystore(path string) (*Keystore, error) {
+	keystore := &Keystore{
+		path:     path,
+		Version:  keystoreVersion,
+		Accounts: map[string]keystoreAccount{},
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return keystore, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = json.Unmarshal(b, keystore)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid keystore %s", path)
+	}
+
+	if keystore.Version != keystoreVersion {
+		return nil, errors.Errorf("unsupported keystore version %d", keystore.Version)
+	}
+
+	if keystore.Accounts == nil {
+		keystore.Accounts = map[string]keystoreAccount{}
+	}
+
+	return keystore, nil
+}
+
+// List returns the accounts sorted by name.
+func (k *Keystore) List() []KeystoreEntry {
+	result := []KeystoreEntry{}
+	for name, acc := range k.Accounts {
+		result = append(result, KeystoreEntry{Name: name, Address: acc.Address})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// Import encrypts the private key with the passphrase and writes the keystore file.
+func (k *Keystore) Import(name string, privateKey ed25519.PrivateKey, passphrase []byte) error {
+	if _, ok := k.Accounts[name]; ok {
+		return errors.Errorf("account %q already exists", name)
+	}
+
+	account, err := crypto.AccountFromPrivateKey(privateKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	acc := keystoreAccount{Address: account.Address.String()}
+	acc.KDF.N, acc.KDF.R, acc.KDF.P = scryptN, scryptR, scryptP
+	acc.KDF.Salt = make([]byte, 32)
+	_, err = rand.Read(acc.KDF.Salt)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	aead, err := acc.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	acc.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(acc.Nonce)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	acc.Ciphertext = aead.Seal(nil, acc.Nonce, privateKey, additionalData(name, acc.Address))
+
+	k.Accounts[name] = acc
+
+	return k.save()
+}
+
+// ImportMnemonic imports the account of the mnemonic words.
+func (k *Keystore) ImportMnemonic(name string, words string, passphrase []byte) error {
+	key, err := mnemonic.ToPrivateKey(strings.TrimSpace(words))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return k.Import(name, key, passphrase)
+}
+
+// Account decrypts the account with the given name.
+func (k *Keystore) Account(name string, passphrase []byte) (crypto.Account, error) {
+	acc, ok := k.Accounts[name]
+	if !ok {
+		return crypto.Account{}, errors.Wrapf(ErrAccountNotFound, "%q", name)
+	}
+
+	aead, err := acc.aead(passphrase)
+	if err != nil {
+		return crypto.Account{}, err
+	}
+
+	privateKey, err := aead.Open(nil, acc.Nonce, acc.Ciphertext, additionalData(name, acc.Address))
+	if err != nil {
+		return crypto.Account{}, errors.Errorf("can't decrypt account %q, wrong passphrase?", name)
+	}
+
+	account, err := crypto.AccountFromPrivateKey(privateKey)
+	if err != nil {
+		return crypto.Account{}, errors.WithStack(err)
+	}
+
+	if account.Address.String() != acc.Address {
+		return crypto.Account{}, errors.Errorf("account %q doesn't match its address", name)
+	}
+
+	return account, nil
+}
+
+// Export returns the mnemonic words of the account.
+func (k *Keystore) Export(name string, passphrase []byte) (string, error) {
+	account, err := k.Account(name, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	words, err := mnemonic.FromPrivateKey(account.PrivateKey)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return words, nil
+}
+
+func (k *Keystore) save() error {
+	b, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return writeFileAtomic(k.path, append(b, '\n'), 0600)
+}
+
+func (a keystoreAccount) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, a.KDF.Salt, a.KDF.N, a.KDF.R, a.KDF.P, 32)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return aead, nil
+}
+
+// additionalData binds the ciphertext to the name and address so entries can't be swapped.
+func additionalData(name string, address string) []byte {
+	return []byte(name + ":" + address)
+}
+
+// Passphrase returns the passphrase of the keystore.
+type Passphrase func() ([]byte, error)
+
+// PassphraseFromEnv reads the passphrase from an environment variable.
+func PassphraseFromEnv(name string) Passphrase {
+	return func() ([]byte, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, errors.Errorf("%s is not set", name)
+		}
+
+		return []byte(value), nil
+	}
+}
+
+// PassphraseFromFile reads the passphrase from the first line of a file.
+func PassphraseFromFile(path string) Passphrase {
+	return func() ([]byte, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		line, _, _ := strings.Cut(string(b), "\n")
+
+		return []byte(strings.TrimRight(line, "\r")), nil
+	}
+}
+
+// PassphrasePrompt asks for the passphrase on the terminal without echoing it.
+func PassphrasePrompt() Passphrase {
+	return func() ([]byte, error) {
+		fmt.Fprint(os.Stderr, "Keystore passphrase: ")
+		defer fmt.Fprintln(os.Stderr)
+
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return passphrase, nil
+	}
+}
+
+// ParsePassphrase returns the passphrase source of "prompt", "env:NAME" or "file:PATH".
+func ParsePassphrase(source string) (Passphrase, error) {
+	kind, value, _ := strings.Cut(source, ":")
+	switch kind {
+	case "prompt":
+		return PassphrasePrompt(), nil
+	case "env":
+		return PassphraseFromEnv(value), nil
+	case "file":
+		return PassphraseFromFile(value), nil
+	default:
+		return nil, errors.Errorf("unknown passphrase source %q", source)
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// The client logs with the logger of the context, every record has the same fields for the same things:
+// app_id, round, sender, tx_id, group_id. Keys, mnemonics, passphrases and API tokens are never logged.
+
+type loggerKey struct{}
+
+// WithLogger returns a context whose client operations log with the logger, they don't log without one.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func loggerFrom(ctx context.Context) *slog.Logger {
+	logger, ok := ctx.Value(loggerKey{}).(*slog.Logger)
+	if !ok || logger == nil {
+		return slog.New(discardHandler{})
+	}
+
+	return logger
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// loggingTransport logs the algod calls, without their headers which hold the API token.
+type loggingTransport struct {
+	logger *slog.Logger
+	base   http.RoundTripper
+}
+
+// NewLoggingTransport returns a transport logging every call at the debug level, for the algod and indexer clients.
+func NewLoggingTransport(logger *slog.Logger, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return loggingTransport{logger: logger, base: base}
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	attrs := []any{"method", req.Method, "path", req.URL.Path, "duration", time.Since(start)}
+	if err != nil {
+		t.logger.WarnContext(req.Context(), "algod call failed", append(attrs, "error", err)...)
+		return resp, err
+	}
+
+	t.logger.DebugContext(req.Context(), "algod call", append(attrs, "status", resp.StatusCode)...)
+
+	return resp, nil
+}
+
+// groupAttrs describe the txs of a group: who pays what to whom and which app they call, the group ID aside.
+func groupAttrs(group []types.SignedTxn) []any {
+	txIDs := make([]string, len(group))
+	txs := make([]any, len(group))
+	for i, stx := range group {
+		tx := stx.Txn
+		txIDs[i] = crypto.GetTxID(tx)
+
+		attrs := []any{"type", string(tx.Type), "sender", tx.Sender.String(), "fee", uint64(tx.Fee)}
+		switch tx.Type {
+		case types.PaymentTx:
+			attrs = append(attrs, "receiver", tx.Receiver.String(), "amount", uint64(tx.Amount))
+		case types.AssetTransferTx:
+			attrs = append(attrs, "receiver", tx.AssetReceiver.String(), "amount", tx.AssetAmount, "asset_id", uint64(tx.XferAsset))
+		case types.ApplicationCallTx:
+			attrs = append(attrs, "app_id", uint64(tx.ApplicationID), "on_complete", tx.OnCompletion)
+		}
+
+		txs[i] = slog.Group(txIDs[i], attrs...)
+	}
+
+	last := group[len(group)-1].Txn
+
+	return []any{
+		"tx_ids", txIDs,
+		"sender", last.Sender.String(),
+		"first_valid", uint64(last.FirstValid),
+		"last_valid", uint64(last.LastValid),
+		slog.Group("txs", txs...),
+	}
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// ErrWrongNetwork is returned when the node isn't on the network of the profile.
+var ErrWrongNetwork = errors.New("the node is on a different network")
+
+// ErrNoProfile is returned when signing with a context without a profile, set it with WithProfile.
+var ErrNoProfile = errors.New("no profile in the context")
+
+// Profile holds everything needed to talk to one network.
+type Profile struct {
+	Algod struct {
+		Endpoint  string
+		APIToken  string
+		UserAgent string
+	}
+	Indexer struct {
+		Endpoint string
+		APIToken string
+	}
+	// APPID is resolved from the registry by AppName when it isn't set.
+	APPID   uint64
+	AppName string
+	// FactoryID is the factory managing the thrones of the lobby.
+	FactoryID uint64
+	// GenesisID and GenesisHash (base64) are checked against the node before signing.
+	GenesisID   string
+	GenesisHash string
+}
+
+// knownNetworks are the genesis of the public networks, a profile with one of these names can't point anywhere else.
+var knownNetworks = map[string]struct{ genesisID, genesisHash string }{
+	"mainnet": {"mainnet-v1.0", "wGHE2Pwdvd7S12BL5FaOP20EGYesN73ktiC1qzkkit8="},
+	"testnet": {"testnet-v1.0", "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI="},
+}
+
+// withKnownGenesis fills the genesis of the public networks and rejects profiles that contradict it.
+func (p Profile) withKnownGenesis(name string) (Profile, error) {
+	known, ok := knownNetworks[name]
+	if !ok {
+		return p, nil
+	}
+
+	if (p.GenesisID != "" && p.GenesisID != known.genesisID) || (p.GenesisHash != "" && p.GenesisHash != known.genesisHash) {
+		return Profile{}, errors.Errorf("profile %s has the genesis of another network", name)
+	}
+
+	p.GenesisID = known.genesisID
+	p.GenesisHash = known.genesisHash
+
+	return p, nil
+}
+
+// CheckGenesis compares the genesis of the suggested params with the profile, the empty fields aren't checked.
+func (p Profile) CheckGenesis(params types.SuggestedParams) error {
+	return p.checkGenesis(params.GenesisID, params.GenesisHash)
+}
+
+// CheckNode asks the node for its genesis and compares it with the profile, the empty fields aren't checked.
+func (p Profile) CheckNode(ctx context.Context, algodClient *algod.Client) error {
+	version, err := algodClient.Versions().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return p.checkGenesis(version.GenesisID, version.GenesisHash)
+}
+
+func (p Profile) checkGenesis(genesisID string, genesisHash []byte) error {
+	if p.GenesisID != "" && genesisID != p.GenesisID {
+		return errors.Wrapf(ErrWrongNetwork, "expected genesis ID %s, got %s", p.GenesisID, genesisID)
+	}
+
+	hash := base64.StdEncoding.EncodeToString(genesisHash)
+	if p.GenesisHash != "" && hash != p.GenesisHash {
+		return errors.Wrapf(ErrWrongNetwork, "expected genesis hash %s, got %s", p.GenesisHash, hash)
+	}
+
+	return nil
+}
+
+type profileKey struct{}
+
+// WithProfile returns a context whose client operations refuse to sign transactions for another network.
+// The operations that sign need it, they don't sign without a profile.
+func WithProfile(ctx context.Context, profile Profile) context.Context {
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+// checkNetwork is called before signing, it asks the node for its genesis and makes sure the transactions are
+// for its network and for the network of the profile in the context.
+func checkNetwork(ctx context.Context, algodClient *algod.Client, params types.SuggestedParams) error {
+	version, err := algodClient.Versions().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// The params may come from the caller, they must not sign for another network than the node's.
+	node := Profile{GenesisID: version.GenesisID, GenesisHash: base64.StdEncoding.EncodeToString(version.GenesisHash)}
+	err = node.CheckGenesis(params)
+	if err != nil {
+		return errors.Wrap(err, "suggested params")
+	}
+
+	profile, ok := ctx.Value(profileKey{}).(Profile)
+	if !ok {
+		return errors.WithStack(ErrNoProfile)
+	}
+
+	return profile.checkGenesis(version.GenesisID, version.GenesisHash)
+}
+
+// MakeAlgodClient returns an algod client of the profile without checking the network, to diagnose the profile.
+// The operations still check the network before signing, use NewAlgodClient to check it upfront.
+func MakeAlgodClient(profile Profile) (*algod.Client, error) {
+	return makeAlgodClient(profile, nil)
+}
+
+// makeAlgodClient is MakeAlgodClient logging its calls with the logger when there is one.
+func makeAlgodClient(profile Profile, logger *slog.Logger) (*algod.Client, error) {
+	headers := []*common.Header{
+		{
+			Key:   "x-api-key",
+			Value: profile.Algod.APIToken,
+		},
+	}
+
+	if profile.Algod.UserAgent != "" {
+		headers = append(headers, &common.Header{Key: "User-Agent", Value: profile.Algod.UserAgent})
+	}
+
+	var transport http.RoundTripper
+	if logger != nil {
+		transport = NewLoggingTransport(logger, nil)
+	}
+
+	algodClient, err := algod.MakeClientWithTransport(profile.Algod.Endpoint, profile.Algod.APIToken, headers, transport)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return algodClient, nil
+}
+
+// MakeIndexerClient returns an indexer client of the profile.
+func MakeIndexerClient(profile Profile) (*indexer.Client, error) {
+	indexerClient, err := indexer.MakeClientWithHeaders(profile.Indexer.Endpoint, profile.Indexer.APIToken, []*common.Header{
+		{
+			Key:   "x-api-key",
+			Value: profile.Indexer.APIToken,
+		},
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return indexerClient, nil
+}
+
+// NewAlgodClient connects to the algod of the profile and checks it is on the expected network,
+// its calls are logged with the logger of the context.
+func NewAlgodClient(ctx context.Context, profile Profile) (*algod.Client, error) {
+	logger, _ := ctx.Value(loggerKey{}).(*slog.Logger)
+	algodClient, err := makeAlgodClient(profile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	err = profile.CheckNode(ctx, algodClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return algodClient, nil
+}
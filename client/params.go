@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/pkg/errors"
+)
+
+// Bounds of the params the admin can change on a live app, the contract enforces them too.
+const (
+	MaxAdminFee          = 10
+	MinRewardMultiplier  = 50
+	MaxRewardMultiplier  = 90
+	MaxAdminFeeAndReward = 95
+	MinReignPeriod       = time.Hour
+	MaxReignPeriod       = 30 * 24 * time.Hour
+)
+
+// Params are the rules of a reign the admin can change, the fees are percentages of the claim price.
+type Params struct {
+	AdminFee         uint64
+	RewardMultiplier uint64
+	ReignPeriod      time.Duration
+}
+
+// ParamChange is a param whose proposed value differs from the one the app uses.
+type ParamChange struct {
+	Name     string
+	Current  string
+	Proposed string
+}
+
+func (c ParamChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Name, c.Current, c.Proposed)
+}
+
+// CurrentParams returns the params of the current reign.
+func (s State) CurrentParams() Params {
+	return Params{
+		AdminFee:         s.AdminFee,
+		RewardMultiplier: s.RewardMultiplier,
+		ReignPeriod:      time.Duration(s.ReignPeriod) * time.Second,
+	}
+}
+
+// ClaimParams returns the params the next claim pays with: a claim after the reign starts the next reign,
+// the contract applies the pending params before checking its amounts.
+func (s State) ClaimParams() Params {
+	if s.PendingParams != nil && s.IsReignEnded() {
+		return *s.PendingParams
+	}
+
+	return s.CurrentParams()
+}
+
+// Validate checks the params are within the bounds the contract accepts.
+func (p Params) Validate() error {
+	if p.AdminFee > MaxAdminFee {
+		return errors.Errorf("admin fee %d is above %d", p.AdminFee, MaxAdminFee)
+	}
+
+	if p.RewardMultiplier < MinRewardMultiplier || p.RewardMultiplier > MaxRewardMultiplier {
+		return errors.Errorf("reward multiplier %d isn't between %d and %d", p.RewardMultiplier, MinRewardMultiplier, MaxRewardMultiplier)
+	}
+
+	if p.AdminFee+p.RewardMultiplier > MaxAdminFeeAndReward {
+		return errors.Errorf("admin fee %d plus reward multiplier %d is above %d", p.AdminFee, p.RewardMultiplier, MaxAdminFeeAndReward)
+	}
+
+	// The contract reads whole seconds.
+	if p.ReignPeriod%time.Second != 0 {
+		return errors.Errorf("reign period %s isn't whole seconds", p.ReignPeriod)
+	}
+
+	if p.ReignPeriod < MinReignPeriod || p.ReignPeriod > MaxReignPeriod {
+		return errors.Errorf("reign period %s isn't between %s and %s", p.ReignPeriod, MinReignPeriod, MaxReignPeriod)
+	}
+
+	return nil
+}
+
+// DiffParams returns the params that change from the current reign of the state to the proposed ones.
+func DiffParams(state State, proposed Params) []ParamChange {
+	current := state.CurrentParams()
+	changes := []ParamChange{}
+
+	if current.AdminFee != proposed.AdminFee {
+		changes = append(changes, ParamChange{"admin fee", fmt.Sprintf("%d%%", current.AdminFee), fmt.Sprintf("%d%%", proposed.AdminFee)})
+	}
+
+	if current.RewardMultiplier != proposed.RewardMultiplier {
+		changes = append(changes, ParamChange{"reward multiplier", fmt.Sprintf("%d%%", current.RewardMultiplier), fmt.Sprintf("%d%%", proposed.RewardMultiplier)})
+	}
+
+	if current.ReignPeriod != proposed.ReignPeriod {
+		changes = append(changes, ParamChange{"reign period", current.ReignPeriod.String(), proposed.ReignPeriod.String()})
+	}
+
+	return changes
+}
+
+// PlanParams reads the state of the app and returns the changes the proposed params would make.
+// The state tells when they apply: at the end of the current reign, or right away when there is no king.
+func PlanParams(ctx context.Context, algodClient *algod.Client, appID uint64, proposed Params) (State, []ParamChange, error) {
+	err := proposed.Validate()
+	if err != nil {
+		return State{}, nil, err
+	}
+
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return State{}, nil, err
+	}
+
+	return state, DiffParams(state, proposed), nil
+}
+
+// SetParams sets the params of the next reign, they replace the pending params if there are some.
+func SetParams(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, params Params, waitRounds uint64) error {
+	state, _, err := PlanParams(ctx, algodClient, appID, params)
+	if err != nil {
+		return err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	_, err = adminClient(algodClient, appID).SetParams(
+		ctx,
+		admin,
+		CallParams{MinFees: 1, WaitRounds: waitRounds},
+		params.AdminFee,
+		params.RewardMultiplier,
+		uint64(params.ReignPeriod.Seconds()),
+	)
+
+	return err
+}
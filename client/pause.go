@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/pkg/errors"
+)
+
+// ErrPaused is returned when claiming a throne the admin paused.
+var ErrPaused = errors.New("the throne is paused")
+
+// Pause stops the claims of the app until Unpause, the king can still abdicate and the admin decommission the app.
+func Pause(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, waitRounds uint64) error {
+	return setPaused(ctx, algodClient, admin, appID, true, waitRounds)
+}
+
+// Unpause lets the players claim the app again.
+func Unpause(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, waitRounds uint64) error {
+	return setPaused(ctx, algodClient, admin, appID, false, waitRounds)
+}
+
+func setPaused(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, paused bool, waitRounds uint64) error {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	app := adminClient(algodClient, appID)
+	call := app.Unpause
+	if paused {
+		call = app.Pause
+	}
+
+	_, err = call(ctx, admin, CallParams{MinFees: 1, WaitRounds: waitRounds})
+
+	return err
+}
@@ -3,13 +3,18 @@ package client
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common"
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
-	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func ReadGlobalState(ctx context.Context, client *algod.Client, address string, appID uint64) ([]models.TealKeyValue, error) {
@@ -18,9 +23,34 @@ func ReadGlobalState(ctx context.Context, client *algod.Client, address string,
 		return nil, errors.WithStack(err)
 	}
 
+	trace.SpanFromContext(ctx).SetAttributes(roundAttr(info.Round))
+
 	return info.CreatedApp.GlobalState, nil
 }
 
+// AccountBalance is the balance of an account and the min balance algod computes for it, which counts its assets,
+// the assets and apps it created and its opt-ins.
+type AccountBalance struct {
+	Amount     uint64 `json:"amount"`
+	MinBalance uint64 `json:"min-balance"`
+}
+
+// accountBalanceParams asks algod for the account without its assets and apps.
+type accountBalanceParams struct {
+	Exclude string `url:"exclude,omitempty"`
+}
+
+// GetAccountBalance reads the balance and min balance of the account, the account model of the SDK drops the min balance.
+func GetAccountBalance(ctx context.Context, algodClient *algod.Client, address string) (AccountBalance, error) {
+	balance := AccountBalance{}
+	err := (*common.Client)(algodClient).Get(ctx, &balance, "/v2/accounts/"+address, accountBalanceParams{Exclude: "all"}, nil)
+	if err != nil {
+		return AccountBalance{}, errors.WithStack(err)
+	}
+
+	return balance, nil
+}
+
 type State struct {
 	Admin            string
 	EndOfReign       time.Time
@@ -30,55 +60,224 @@ type State struct {
 	InitPrice        uint64
 	King             string
 	AdminFee         uint64
+	// AssetID is the ASA the throne is priced in, zero when it is priced in ALGO.
+	AssetID uint64
+	// Reign is the number of claims so far.
+	Reign uint64
+	// Crown is the crown minted for the last king, zero before the first claim.
+	Crown uint64
+	// Crowns is the number of crowns the app created and didn't burn, their min balance stays in the app account.
+	Crowns uint64
+	// PendingAdmin is the admin proposed by the admin, empty when there is none.
+	PendingAdmin string
+	// Paused tells if the admin paused the claims.
+	Paused bool
+	// PendingParams are the params of the next reign set by the admin, nil when there are none.
+	PendingParams *Params
+	// UpgradeDelay is the delay between the announce of an upgrade or delete and its execution.
+	UpgradeDelay time.Duration
+	// PendingUpgrade is the upgrade or delete announced by the admin, nil when there is none.
+	PendingUpgrade *Upgrade
+	// Raw holds the global state as read, keyed by the decoded key.
+	Raw map[string]models.TealValue
 }
 
-func FormatState(rawState []models.TealKeyValue) (State, error) {
-	state := State{}
+// IsReignEnded tells if the next claim starts a new reign at the init price.
+func (s State) IsReignEnded() bool {
+	return s.EndOfReign.Before(time.Now())
+}
+
+// ClaimPrice is the amount the next king has to pay.
+func (s State) ClaimPrice() uint64 {
+	if s.IsReignEnded() {
+		return s.InitPrice
+	}
+
+	return s.KingPrice
+}
+
+// ClaimCrownFunding is what the next claim pays on top of the price for the min balance of the crown it mints,
+// the variant priced in an ASA mints no crown.
+func (s State) ClaimCrownFunding() uint64 {
+	if s.AssetID != 0 {
+		return 0
+	}
+
+	return crownMinBalance
+}
+
+// ClaimInnerTxs is the number of inner txs of the next claim: the payment of the dead king, and the mint of the crown.
+func (s State) ClaimInnerTxs() uint64 {
+	innerTxs := uint64(0)
+	if s.King != "" && s.IsReignEnded() {
+		innerTxs++
+	}
+
+	if s.AssetID == 0 {
+		innerTxs++
+	}
+
+	return innerTxs
+}
+
+// ClaimFees are the min fees of the next claim group, including its inner txs.
+func (s State) ClaimFees(minFee uint64) uint64 {
+	// The admin fee, compensation and app call, and the reward when there is a king.
+	txs := uint64(3)
+	if s.King != "" {
+		txs++
+	}
+
+	return minFee * (txs + s.ClaimInnerTxs())
+}
+
+// Types of the TEAL values.
+const (
+	tealBytesType = 1
+	tealUintType  = 2
+)
+
+// optionalStateKeys are the global state keys the admin sets and removes, every other key of the variant
+// is set when the app is created.
+var optionalStateKeys = map[string]bool{
+	"pending_admin":          true,
+	"paused":                 true,
+	"next_admin_fee":         true,
+	"next_reward_multiplier": true,
+	"next_reign_period":      true,
+	"upgrade_hash":           true,
+	"upgrade_at":             true,
+}
+
+// FormatStateStrict is like FormatState but fails on missing or unexpected keys and on type mismatches,
+// so a schema change can't show up as zero values. The keys are those of the variant, priced in an ASA
+// when the state has an asset.
+func FormatStateStrict(rawState []models.TealKeyValue) (State, error) {
+	values := map[string]models.TealValue{}
 	for _, keyValue := range rawState {
 		key, err := base64.StdEncoding.DecodeString(keyValue.Key)
 		if err != nil {
 			return State{}, errors.WithStack(err)
 		}
 
-		switch string(key) {
-		case "king":
-			adr, err := decodeAddress(keyValue.Value.Bytes)
-			if err != nil {
-				return State{}, errors.WithStack(err)
-			}
+		values[string(key)] = keyValue.Value
+	}
+
+	stateTypes := kingOfAlgoGlobalStateTypes
+	if _, ok := values["asset"]; ok {
+		stateTypes = kingOfAlgoASAGlobalStateTypes
+	}
 
-			state.King = adr
-		case "admin":
-			adr, err := decodeAddress(keyValue.Value.Bytes)
-			if err != nil {
-				return State{}, errors.WithStack(err)
-			}
+	for key, value := range values {
+		expectedType, ok := stateTypes[key]
+		if !ok {
+			return State{}, errors.Errorf("unexpected global state key %q", key)
+		}
 
-			state.Admin = adr
+		if value.Type != expectedType {
+			return State{}, errors.Errorf("global state key %q has type %d, expected %d", key, value.Type, expectedType)
+		}
+	}
 
-		case "end_of_reign_timestamp":
-			state.EndOfReign = time.Unix(int64(keyValue.Value.Uint), 0)
+	missing := []string{}
+	for key := range stateTypes {
+		if _, ok := values[key]; !ok && !optionalStateKeys[key] {
+			missing = append(missing, key)
+		}
+	}
 
-		case "king_price":
-			state.KingPrice = keyValue.Value.Uint
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return State{}, errors.Errorf("missing global state keys: %s", strings.Join(missing, ", "))
+	}
 
-		case "reward_multiplier":
-			state.RewardMultiplier = keyValue.Value.Uint
+	return FormatState(rawState)
+}
 
-		case "reign_period":
-			state.ReignPeriod = keyValue.Value.Uint
+// FormatState reads the state from the global state decoded as the app specs describe it.
+func FormatState(rawState []models.TealKeyValue) (State, error) {
+	global, err := DecodeAppGlobalState(rawState)
+	if err != nil {
+		return State{}, err
+	}
 
-		case "init_price":
-			state.InitPrice = keyValue.Value.Uint
+	state := State{
+		Admin:            global.Admin,
+		EndOfReign:       time.Unix(int64(global.EndOfReignTimestamp), 0),
+		KingPrice:        global.KingPrice,
+		RewardMultiplier: global.RewardMultiplier,
+		ReignPeriod:      global.ReignPeriod,
+		InitPrice:        global.InitPrice,
+		King:             global.King,
+		AdminFee:         global.AdminFee,
+		AssetID:          global.Asset,
+		Reign:            global.Reign,
+		Crown:            global.Crown,
+		Crowns:           global.Crowns,
+		PendingAdmin:     global.PendingAdmin,
+		Paused:           global.Paused != 0,
+		UpgradeDelay:     time.Duration(global.UpgradeDelay) * time.Second,
+		Raw:              global.Raw,
+	}
 
-		case "admin_fee":
-			state.AdminFee = keyValue.Value.Uint
+	// The params of the next reign are set together.
+	if _, ok := global.Raw["next_admin_fee"]; ok {
+		state.PendingParams = &Params{
+			AdminFee:         global.NextAdminFee,
+			RewardMultiplier: global.NextRewardMultiplier,
+			ReignPeriod:      time.Duration(global.NextReignPeriod) * time.Second,
 		}
 	}
 
+	if _, ok := global.Raw["upgrade_hash"]; ok {
+		upgrade := formatUpgrade(global.UpgradeHash, global.UpgradeAt)
+		state.PendingUpgrade = &upgrade
+	}
+
 	return state, nil
 }
 
+// Validate checks the invariants of the contract on the state.
+func (s State) Validate() error {
+	problems := []string{}
+
+	if s.AdminFee+s.RewardMultiplier > 100 {
+		problems = append(problems, fmt.Sprintf("admin fee %d plus reward multiplier %d is above 100", s.AdminFee, s.RewardMultiplier))
+	}
+
+	if s.InitPrice == 0 {
+		problems = append(problems, "init price is 0")
+	} else {
+		if s.KingPrice < s.InitPrice {
+			problems = append(problems, fmt.Sprintf("king price %d is below the init price %d", s.KingPrice, s.InitPrice))
+		}
+
+		// The price doubles on every claim.
+		multiple := s.KingPrice / s.InitPrice
+		if s.KingPrice%s.InitPrice != 0 || multiple&(multiple-1) != 0 {
+			problems = append(problems, fmt.Sprintf("king price %d isn't the init price %d times a power of two", s.KingPrice, s.InitPrice))
+		}
+	}
+
+	if s.Admin == "" {
+		problems = append(problems, "admin is empty")
+	} else if _, err := types.DecodeAddress(s.Admin); err != nil {
+		problems = append(problems, fmt.Sprintf("admin %q isn't a valid address", s.Admin))
+	}
+
+	if s.King != "" {
+		if _, err := types.DecodeAddress(s.King); err != nil {
+			problems = append(problems, fmt.Sprintf("king %q isn't a valid address", s.King))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid state: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
 func decodeAddress(b string) (string, error) {
 	if len(b) == 0 {
 		return "", nil
@@ -97,13 +296,29 @@ func decodeAddress(b string) (string, error) {
 	return address, nil
 }
 
-func GetContractState(ctx context.Context, client *algod.Client, owner crypto.Account, appID uint64) (State, error) {
+// GetAppState reads the app state by app ID, it doesn't need the creator.
+func GetAppState(ctx context.Context, client *algod.Client, appID uint64) (state State, err error) {
+	ctx, span := startSpan(ctx, "GetAppState", appIDAttr(appID))
+	defer func() { endSpan(span, err) }()
+
+	app, err := client.GetApplicationByID(appID).Do(ctx)
+	if err != nil {
+		return State{}, errors.WithStack(err)
+	}
+
+	return FormatState(app.Params.GlobalState)
+}
+
+func GetContractState(ctx context.Context, client *algod.Client, owner Account, appID uint64) (formattedState State, err error) {
+	ctx, span := startSpan(ctx, "GetContractState", appIDAttr(appID), attribute.String("owner", owner.Address.String()))
+	defer func() { endSpan(span, err) }()
+
 	state, err := ReadGlobalState(ctx, client, owner.Address.String(), appID)
 	if err != nil {
 		return State{}, errors.WithStack(err)
 	}
 
-	formattedState, err := FormatState(state)
+	formattedState, err = FormatState(state)
 	if err != nil {
 		return State{}, errors.WithStack(err)
 	}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// Default bounds of GetContractStates.
+const (
+	defaultStatesConcurrency = 8
+	defaultStatesBurst       = 1
+)
+
+// StatesOptions bounds the load GetContractStates puts on the node.
+type StatesOptions struct {
+	// Concurrency is the max number of requests in flight, it defaults to 8.
+	Concurrency int
+	// RequestsPerSecond limits the request rate, zero means no limit.
+	RequestsPerSecond float64
+}
+
+// AppState is the state of one app, or why it couldn't be read.
+type AppState struct {
+	AppID uint64
+	State State
+	Err   error
+}
+
+// GetContractStates reads the state of many apps concurrently by app ID, so no owner account is needed.
+// The results are in the order of the app IDs and a failed app doesn't stop the others.
+func GetContractStates(ctx context.Context, client *algod.Client, appIDs []uint64, opts StatesOptions) []AppState {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultStatesConcurrency
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, defaultStatesBurst)
+	if opts.RequestsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.RequestsPerSecond), defaultStatesBurst)
+	}
+
+	sem := semaphore.NewWeighted(int64(concurrency))
+	results := make([]AppState, len(appIDs))
+	wg := sync.WaitGroup{}
+	for i, appID := range appIDs {
+		results[i].AppID = appID
+
+		err := sem.Acquire(ctx, 1)
+		if err != nil {
+			// The context is done, the apps left aren't read.
+			for j := i; j < len(appIDs); j++ {
+				results[j] = AppState{AppID: appIDs[j], Err: errors.WithStack(err)}
+			}
+
+			break
+		}
+
+		wg.Add(1)
+		go func(result *AppState) {
+			defer wg.Done()
+			defer sem.Release(1)
+
+			err := limiter.Wait(ctx)
+			if err != nil {
+				result.Err = errors.WithStack(err)
+				return
+			}
+
+			state, err := GetAppState(ctx, client, result.AppID)
+			if err != nil {
+				result.Err = errors.Wrapf(err, "app %d", result.AppID)
+				return
+			}
+
+			result.State = state
+		}(&results[i])
+	}
+
+	wg.Wait()
+
+	return results
+}
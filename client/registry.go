@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAppNotFound is returned when the registry has no app with the requested name.
+var ErrAppNotFound = errors.New("app not found in the registry")
+
+// RegistryEntry describes a deployed app.
+type RegistryEntry struct {
+	Name         string        `json:"name"`
+	AppID        uint64        `json:"appId"`
+	Version      string        `json:"version"`
+	Creator      string        `json:"creator"`
+	CreatedRound uint64        `json:"createdRound"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	ReignPeriod  time.Duration `json:"reignPeriod"`
+	AssetID      uint64        `json:"assetId,omitempty"`
+	Note         string        `json:"note,omitempty"`
+}
+
+type registryNetwork struct {
+	GenesisID string          `json:"genesisId"`
+	Apps      []RegistryEntry `json:"apps"`
+}
+
+// Registry is a file of deployed apps keyed by the genesis hash of their network.
+type Registry struct {
+	path     string
+	Networks map[string]*registryNetwork `json:"networks"`
+}
+
+// LoadRegistry reads the registry file, a missing file is an empty registry.
+func LoadRegistry(path string) (*Registry, error) {
+	registry := &Registry{
+		path:     path,
+		Networks: map[string]*registryNetwork{},
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = json.Unmarshal(b, registry)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid registry %s", path)
+	}
+
+	if registry.Networks == nil {
+		registry.Networks = map[string]*registryNetwork{}
+	}
+
+	return registry, nil
+}
+
+// Add records an app of the network with the given genesis and writes the registry file.
+// Names are unique per network.
+func (r *Registry) Add(genesisID string, genesisHash []byte, entry RegistryEntry) error {
+	key := base64.StdEncoding.EncodeToString(genesisHash)
+
+	network, ok := r.Networks[key]
+	if !ok {
+		network = &registryNetwork{GenesisID: genesisID}
+		r.Networks[key] = network
+	}
+
+	for _, app := range network.Apps {
+		if app.Name == entry.Name {
+			return errors.Errorf("app %q is already registered on %s with ID %d", entry.Name, genesisID, app.AppID)
+		}
+	}
+
+	network.Apps = append(network.Apps, entry)
+
+	return r.save()
+}
+
+// Lookup finds an app by name. When the genesis hash is empty the name has to be unique across networks.
+func (r *Registry) Lookup(genesisHash string, name string) (RegistryEntry, error) {
+	found := []RegistryEntry{}
+	for key, network := range r.Networks {
+		if genesisHash != "" && key != genesisHash {
+			continue
+		}
+
+		for _, app := range network.Apps {
+			if app.Name == name {
+				found = append(found, app)
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return RegistryEntry{}, errors.Wrapf(ErrAppNotFound, "%q", name)
+	case 1:
+		return found[0], nil
+	default:
+		return RegistryEntry{}, errors.Errorf("app %q is registered on %d networks, set the genesis hash", name, len(found))
+	}
+}
+
+// Apps returns the apps of the network with the given genesis hash.
+func (r *Registry) Apps(genesisHash string) []RegistryEntry {
+	network, ok := r.Networks[genesisHash]
+	if !ok {
+		return nil
+	}
+
+	return network.Apps
+}
+
+func (r *Registry) save() error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return writeFileAtomic(r.path, append(b, '\n'), 0644)
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// ErrExpired is returned when the validity window of a group passed before it was confirmed.
+	ErrExpired = errors.New("the validity window passed before the confirmation")
+	// ErrRejected is returned when the node drops a group from its pool.
+	ErrRejected = errors.New("the node rejected the transaction")
+	// ErrNotConfirmed is returned when a group isn't confirmed after the rounds waited, it can still be confirmed.
+	ErrNotConfirmed = errors.New("the transaction isn't confirmed yet")
+	// ErrClaimPending is returned when another claim of the account holds the lease of its claims.
+	ErrClaimPending = errors.New("another claim of the account is pending")
+	// ErrStateChanged is returned when the state of the app changed while an expired claim waited.
+	ErrStateChanged = errors.New("the state changed since the claim was built")
+)
+
+// SubmitOptions tell how to submit a group.
+type SubmitOptions struct {
+	// Retries of a transient algod error, the backoff doubles from Backoff between them.
+	Retries int
+	Backoff time.Duration
+	// Resubmits is how many times a claim whose validity window passed is rebuilt with a new one.
+	Resubmits int
+}
+
+// DefaultSubmitOptions retry a transient algod error 5 times over about 3 seconds and resubmit an expired claim twice.
+var DefaultSubmitOptions = SubmitOptions{Retries: 5, Backoff: 100 * time.Millisecond, Resubmits: 2}
+
+// isTransient tells if an algod call failed for a reason that may go away: the node or the network.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// The SDK only tells the status code in the message.
+	message := err.Error()
+	if strings.HasPrefix(message, "HTTP 5") || strings.HasPrefix(message, "HTTP 429") {
+		return true
+	}
+
+	return strings.Contains(message, "connection reset") || strings.Contains(message, "EOF")
+}
+
+// retry runs the algod call until it succeeds, fails for a reason that isn't transient or runs out of retries.
+func retry[T any](ctx context.Context, opts SubmitOptions, call func() (T, error)) (T, error) {
+	backoff := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		result, err := call()
+		if err == nil || !isTransient(err) || attempt >= opts.Retries {
+			return result, errors.WithStack(err)
+		}
+
+		loggerFrom(ctx).WarnContext(ctx, "retrying algod call", "attempt", attempt+1, "backoff", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return result, errors.WithStack(ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// claimLease is the lease of the claims of the account on the reign of the app: the node rejects a claim while
+// another one of the account for the same reign is within its validity window, so a restarted bot can't claim twice.
+func claimLease(appID uint64, sender types.Address, reign uint64) [32]byte {
+	data := []byte("kingOfAlgo/claim")
+	data = binary.BigEndian.AppendUint64(data, appID)
+	data = append(data, sender[:]...)
+	data = binary.BigEndian.AppendUint64(data, reign)
+
+	return sha256.Sum256(data)
+}
+
+// sendGroup sends the signed group, retrying transient errors. A group already in the pool or the ledger counts as sent.
+func sendGroup(ctx context.Context, algodClient *algod.Client, rawTxn []byte, opts SubmitOptions) (err error) {
+	group, err := decodeSignedGroup(rawTxn)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := startSpan(ctx, "sendGroup", append(groupSpanAttrs(group), senderAttr(group[len(group)-1].Txn.Sender))...)
+	defer func() { endSpan(span, err) }()
+
+	logger := loggerFrom(ctx).With("group_id", groupID(group))
+	logger.InfoContext(ctx, "sending group", groupAttrs(group)...)
+
+	_, err = retry(ctx, opts, func() (string, error) {
+		return algodClient.SendRawTransaction(rawTxn).Do(ctx)
+	})
+	switch {
+	case err == nil:
+		logger.InfoContext(ctx, "group sent")
+		return nil
+	case strings.Contains(err.Error(), "overlapping lease"):
+		logger.WarnContext(ctx, "group refused, the lease is held", "error", err)
+		return errors.Wrap(ErrClaimPending, err.Error())
+	case strings.Contains(err.Error(), "already in ledger") || strings.Contains(err.Error(), "transaction already in pool"):
+		logger.InfoContext(ctx, "group already sent")
+		return nil
+	case !isTransient(err):
+		logger.WarnContext(ctx, "group rejected", "error", err)
+		return errors.Wrap(ErrRejected, err.Error())
+	default:
+		return err
+	}
+}
+
+// waitTransaction waits for the confirmation of the tx until the last valid round of its group or for waitRounds,
+// whichever comes first, zero waits until the last valid round.
+func waitTransaction(ctx context.Context, algodClient *algod.Client, txID string, lastValid uint64, waitRounds uint64, opts SubmitOptions) (info models.PendingTransactionInfoResponse, err error) {
+	ctx, span := startSpan(ctx, "waitTransaction", attribute.String("tx_id", txID), attribute.Int64("last_valid", int64(lastValid)))
+	defer func() {
+		if err == nil {
+			span.SetAttributes(roundAttr(info.ConfirmedRound))
+		}
+		endSpan(span, err)
+	}()
+
+	status, err := retry(ctx, opts, func() (models.NodeStatus, error) {
+		return algodClient.Status().Do(ctx)
+	})
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+
+	logger := loggerFrom(ctx).With("tx_id", txID)
+	round := status.LastRound
+	untilRound := round + waitRounds
+	if waitRounds == 0 {
+		untilRound = lastValid + 1
+	}
+	for {
+		info, err := retry(ctx, opts, func() (models.PendingTransactionInfoResponse, error) {
+			info, _, err := algodClient.PendingTransactionInformation(txID).Do(ctx)
+			return info, err
+		})
+		// The node forgets a tx it dropped from its pool.
+		if err != nil && !strings.Contains(err.Error(), "HTTP 404") {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+
+		if err == nil && info.ConfirmedRound > 0 {
+			logger.InfoContext(ctx, "transaction confirmed", "round", info.ConfirmedRound, "app_id", info.ApplicationIndex)
+			return info, nil
+		}
+
+		if err == nil && info.PoolError != "" {
+			logger.WarnContext(ctx, "transaction dropped from the pool", "round", round, "error", info.PoolError)
+			return info, errors.Wrapf(ErrRejected, "tx %s: %s", txID, info.PoolError)
+		}
+
+		if round > lastValid {
+			logger.WarnContext(ctx, "transaction expired", "round", round, "last_valid", lastValid)
+			return info, errors.Wrapf(ErrExpired, "tx %s after round %d", txID, lastValid)
+		}
+
+		if round >= untilRound {
+			logger.WarnContext(ctx, "transaction not confirmed yet", "round", round, "last_valid", lastValid)
+			return info, errors.Wrapf(ErrNotConfirmed, "tx %s at round %d", txID, round)
+		}
+
+		_, err = retry(ctx, opts, func() (models.NodeStatus, error) {
+			return algodClient.StatusAfterBlock(round).Do(ctx)
+		})
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+
+		round++
+	}
+}
+
+// sendWaitGroup sends the signed group and waits for the confirmation of its last tx, up to waitRounds.
+func sendWaitGroup(ctx context.Context, algodClient *algod.Client, rawTxn []byte, waitRounds uint64, opts SubmitOptions) (models.PendingTransactionInfoResponse, error) {
+	// The last tx of the group tells its validity window and returns what the app call did, like in submitGroup.
+	group, err := decodeSignedGroup(rawTxn)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+
+	err = sendGroup(ctx, algodClient, rawTxn, opts)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+
+	last := group[len(group)-1].Txn
+
+	return waitTransaction(ctx, algodClient, crypto.GetTxID(last), uint64(last.LastValid), waitRounds, opts)
+}
+
+// sameClaim tells if a claim built on the before state still pays the right amounts to the right accounts.
+func sameClaim(before State, after State) bool {
+	return before.King == after.King &&
+		before.Admin == after.Admin &&
+		before.Reign == after.Reign &&
+		before.Crown == after.Crown &&
+		before.Paused == after.Paused &&
+		before.ClaimParams() == after.ClaimParams() &&
+		before.IsReignEnded() == after.IsReignEnded() &&
+		before.ClaimPrice() == after.ClaimPrice()
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The client traces its operations with the global tracer provider, they aren't traced until one is set.
+// The spans carry the same attributes as the logs: app_id, round, sender, tx_ids, group_id.
+const tracerName = "github.com/qrksp/king-of-algo/client"
+
+// ServiceName is the service of the spans of the client.
+const ServiceName = "king-of-algo"
+
+// NewOTLPTracerProvider returns a provider exporting the spans to the OTLP collector at the URL over HTTP,
+// like http://localhost:4318. Set it with otel.SetTracerProvider and shut it down to flush the last spans.
+func NewOTLPTracerProvider(ctx context.Context, endpointURL string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpointURL))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(tracingResource())), nil
+}
+
+// NewStdoutTracerProvider returns a provider writing every span to w as soon as it ends, for the tests.
+func NewStdoutTracerProvider(w io.Writer) (*sdktrace.TracerProvider, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter), sdktrace.WithResource(tracingResource())), nil
+}
+
+func tracingResource() *resource.Resource {
+	return resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName))
+}
+
+// startSpan starts a span of the operation as a child of the span of the context, with the provider set last.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error of the operation on its span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+func appIDAttr(appID uint64) attribute.KeyValue {
+	return attribute.Int64("app_id", int64(appID))
+}
+
+func roundAttr(round uint64) attribute.KeyValue {
+	return attribute.Int64("round", int64(round))
+}
+
+func senderAttr(sender types.Address) attribute.KeyValue {
+	return attribute.String("sender", sender.String())
+}
+
+// groupSpanAttrs tag a span with the IDs and the validity window of the group.
+func groupSpanAttrs(group []types.SignedTxn) []attribute.KeyValue {
+	txIDs := make([]string, len(group))
+	for i, stx := range group {
+		txIDs[i] = crypto.GetTxID(stx.Txn)
+	}
+
+	last := group[len(group)-1].Txn
+
+	return []attribute.KeyValue{
+		attribute.String("group_id", groupID(group)),
+		attribute.StringSlice("tx_ids", txIDs),
+		attribute.Int64("first_valid", int64(last.FirstValid)),
+		attribute.Int64("last_valid", int64(last.LastValid)),
+	}
+}
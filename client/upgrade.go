@@ -0,0 +1,237 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+// ErrNoUpgrade is returned when executing or cancelling an upgrade that wasn't announced.
+var ErrNoUpgrade = errors.New("no upgrade is announced")
+
+// ErrTimelocked is returned when executing an upgrade or a delete before its delay passed.
+var ErrTimelocked = errors.New("the upgrade delay hasn't passed")
+
+// deleteAnnouncement is stored instead of the hash of the programs when the admin announces a delete.
+const deleteAnnouncement = "delete"
+
+// Upgrade is an upgrade or a delete announced by the admin, it can run from At.
+type Upgrade struct {
+	// Hash is the sha256 of the approval and clear programs of the upgrade, nil for a delete.
+	Hash   []byte
+	Delete bool
+	At     time.Time
+}
+
+// Ready tells if the delay of the upgrade passed.
+func (u Upgrade) Ready() bool {
+	return !u.At.After(time.Now())
+}
+
+func (u Upgrade) String() string {
+	if u.Delete {
+		return fmt.Sprintf("delete at %s", u.At)
+	}
+
+	return fmt.Sprintf("upgrade to %s at %s", hex.EncodeToString(u.Hash), u.At)
+}
+
+func formatUpgrade(announcement []byte, at uint64) Upgrade {
+	upgrade := Upgrade{At: time.Unix(int64(at), 0)}
+	if string(announcement) == deleteAnnouncement {
+		upgrade.Delete = true
+	} else {
+		upgrade.Hash = announcement
+	}
+
+	return upgrade
+}
+
+// AppUpgrade is the pending upgrade of an app, or why its state couldn't be read.
+type AppUpgrade struct {
+	AppID   uint64
+	Upgrade Upgrade
+	Err     error
+}
+
+// ListPendingUpgrades returns the announced upgrades and deletes of the apps, so the players can see them before they run.
+// The apps without a pending upgrade are left out.
+func ListPendingUpgrades(ctx context.Context, algodClient *algod.Client, appIDs []uint64, opts StatesOptions) []AppUpgrade {
+	upgrades := []AppUpgrade{}
+	for _, appState := range GetContractStates(ctx, algodClient, appIDs, opts) {
+		if appState.Err != nil {
+			upgrades = append(upgrades, AppUpgrade{AppID: appState.AppID, Err: appState.Err})
+			continue
+		}
+
+		if appState.State.PendingUpgrade != nil {
+			upgrades = append(upgrades, AppUpgrade{AppID: appState.AppID, Upgrade: *appState.State.PendingUpgrade})
+		}
+	}
+
+	return upgrades
+}
+
+// CompileUpgrade compiles the TEAL sources of an upgrade and returns the programs with their hash.
+func CompileUpgrade(ctx context.Context, algodClient *algod.Client, approvalSource []byte, clearSource []byte) ([]byte, []byte, []byte, error) {
+	approvalProgram, clearProgram, err := compileSources(ctx, algodClient, approvalSource, clearSource)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return approvalProgram, clearProgram, programsHash(approvalProgram, clearProgram), nil
+}
+
+// AnnounceUpgrade announces the compiled programs the app will run, the admin can update the app to them
+// with ExecuteUpgrade once the upgrade delay of the app passed. Announcing again replaces the upgrade and restarts the delay.
+func AnnounceUpgrade(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, approvalProgram []byte, clearProgram []byte, waitRounds uint64) (Upgrade, error) {
+	hash := [32]byte{}
+	copy(hash[:], programsHash(approvalProgram, clearProgram))
+
+	return announce(ctx, algodClient, admin, appID, func(app KingOfAlgoClient, params CallParams) (MethodResult, error) {
+		return app.AnnounceUpgrade(ctx, admin, params, hash)
+	}, waitRounds)
+}
+
+// AnnounceDelete announces the delete of the app, Decommission can run once the upgrade delay of the app passed.
+func AnnounceDelete(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, waitRounds uint64) (Upgrade, error) {
+	return announce(ctx, algodClient, admin, appID, func(app KingOfAlgoClient, params CallParams) (MethodResult, error) {
+		return app.AnnounceDelete(ctx, admin, params)
+	}, waitRounds)
+}
+
+func announce(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, call func(KingOfAlgoClient, CallParams) (MethodResult, error), waitRounds uint64) (Upgrade, error) {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return Upgrade{}, errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	_, err = call(adminClient(algodClient, appID), CallParams{MinFees: 1, WaitRounds: waitRounds})
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	// The contract sets the time from the block, read it back rather than guessing it.
+	state, err = GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return Upgrade{}, err
+	}
+
+	if state.PendingUpgrade == nil {
+		return Upgrade{}, errors.Wrapf(ErrNoUpgrade, "app %d", appID)
+	}
+
+	return *state.PendingUpgrade, nil
+}
+
+// CancelUpgrade removes the announced upgrade or delete of the app.
+func CancelUpgrade(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, waitRounds uint64) error {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	if state.PendingUpgrade == nil {
+		return errors.Wrapf(ErrNoUpgrade, "app %d", appID)
+	}
+
+	_, err = adminClient(algodClient, appID).CancelUpgrade(ctx, admin, CallParams{MinFees: 1, WaitRounds: waitRounds})
+
+	return err
+}
+
+// ExecuteUpgrade updates the app to the announced programs once the upgrade delay passed.
+// The programs can't need more pages than the app got at creation.
+func ExecuteUpgrade(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, approvalProgram []byte, clearProgram []byte, waitRounds uint64) error {
+	state, err := GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return err
+	}
+
+	if state.Admin != admin.Address.String() {
+		return errors.Wrapf(ErrNotAdmin, "%s of app %d", admin.Address, appID)
+	}
+
+	if state.PendingUpgrade == nil || state.PendingUpgrade.Delete {
+		return errors.Wrapf(ErrNoUpgrade, "app %d", appID)
+	}
+
+	if !bytes.Equal(state.PendingUpgrade.Hash, programsHash(approvalProgram, clearProgram)) {
+		return errors.Errorf("the programs don't match the upgrade announced for app %d", appID)
+	}
+
+	if !state.PendingUpgrade.Ready() {
+		return errors.Wrapf(ErrTimelocked, "app %d until %s", appID, state.PendingUpgrade.At)
+	}
+
+	return updateApp(ctx, algodClient, admin, appID, approvalProgram, clearProgram, waitRounds)
+}
+
+// updateApp sends the update of the app to the programs, the app checks they were announced.
+func updateApp(ctx context.Context, algodClient *algod.Client, admin Account, appID uint64, approvalProgram []byte, clearProgram []byte, waitRounds uint64) error {
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = checkNetwork(ctx, algodClient, suggestedParams)
+	if err != nil {
+		return err
+	}
+
+	tx, err := transaction.MakeApplicationUpdateTx(
+		appID,
+		nil,
+		nil,
+		nil,
+		nil,
+		approvalProgram,
+		clearProgram,
+		suggestedParams,
+		admin.Address,
+		[]byte(fmt.Sprintf(noteFormat, "upgrade")),
+		types.Digest{},
+		[32]byte{},
+		types.ZeroAddress,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	signedBytes, _, err := signTransactions(ctx, admin, []types.Transaction{tx})
+	if err != nil {
+		return err
+	}
+
+	_, err = sendWaitTransaction(ctx, algodClient, signedBytes, waitRounds)
+
+	return err
+}
+
+// checkDeleteAnnounced fails unless the delete of the app was announced and its delay passed.
+func checkDeleteAnnounced(upgrade *Upgrade, appID uint64) error {
+	if upgrade == nil || !upgrade.Delete {
+		return errors.Wrapf(ErrNoUpgrade, "announce the delete of app %d first", appID)
+	}
+
+	if !upgrade.Ready() {
+		return errors.Wrapf(ErrTimelocked, "app %d until %s", appID, upgrade.At)
+	}
+
+	return nil
+}
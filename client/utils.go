@@ -1,42 +1,51 @@
 package client
 
 import (
-	"fmt"
-	"io"
+	"encoding/binary"
 	"io/fs"
 	"math"
 	"os"
-	"time"
+	"path/filepath"
 
 	"github.com/pkg/errors"
 )
 
-func openFile(fileName string) ([]byte, error) {
-	f, err := os.Open(fileName)
+func multiplyPercentage(amount uint64, percentage uint64) uint64 {
+	return uint64(math.Ceil(float64(amount) * float64(percentage) / 100))
+}
+
+// uint64Arg encodes an app argument the contract reads with btoi.
+func uint64Arg(value uint64) []byte {
+	arg := make([]byte, 8)
+	binary.BigEndian.PutUint64(arg, value)
+
+	return arg
+}
+
+// writeFileAtomic writes to a temp file and renames it so a crash doesn't leave a truncated file.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return errors.WithStack(err)
 	}
 
-	defer f.Close()
+	defer os.Remove(tmp.Name())
 
-	fileBytes, err := io.ReadAll(f)
+	_, err = tmp.Write(data)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		tmp.Close()
+		return errors.WithStack(err)
 	}
 
-	return fileBytes, nil
-}
-
-func saveAppID(appID uint64) error {
-	err := os.WriteFile(
-		fmt.Sprintf("new-app-%s", time.Now()),
-		[]byte(fmt.Sprintf("{\"AppID\": %d}", appID)),
-		fs.ModePerm,
-	)
+	err = tmp.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
-	return errors.WithStack(err)
-}
+	err = os.Chmod(tmp.Name(), perm)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
-func multiplyPercentage(amount uint64, percentage uint64) uint64 {
-	return uint64(math.Ceil(float64(amount) * float64(percentage) / 100))
+	return errors.WithStack(os.Rename(tmp.Name(), path))
 }
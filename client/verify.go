@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+// Verification is the result of comparing an on-chain app with the local sources.
+type Verification struct {
+	AppID   uint64
+	Version string
+	Creator string
+	Admin   string
+
+	ApprovalMatch bool
+	ClearMatch    bool
+	SchemaMatch   bool
+	// CreatorMatch is false when an expected creator was given and the app was created by someone else.
+	CreatorMatch bool
+	// AdminMatch is false when the admin in the global state isn't the expected admin,
+	// the creator when none was given.
+	AdminMatch bool
+
+	// Detected is the embedded contract version the programs of the app match, it differs from Version when the app
+	// runs another one and is empty when it runs none of them.
+	Detected string
+
+	// ApprovalDiff and ClearDiff hold the disassembly diff of the programs that don't match.
+	ApprovalDiff string
+	ClearDiff    string
+}
+
+// v1StateSchema is the global schema of the v1 apps: the prices, end of reign, reign period, admin fee and
+// reward multiplier, the king and the admin.
+var v1StateSchema = types.StateSchema{NumUint: 6, NumByteSlice: 2}
+
+// OK tells if the app runs the published contract.
+func (v Verification) OK() bool {
+	return v.ApprovalMatch && v.ClearMatch && v.SchemaMatch && v.CreatorMatch && v.AdminMatch
+}
+
+// VerifyApp compares the programs and global schema of an app with the TEAL of the given contract version.
+// The expected creator is optional, when empty any creator is accepted. The expected admin is optional too,
+// when empty the admin has to be the creator, as it is until the admin is rotated.
+func VerifyApp(ctx context.Context, algodClient *algod.Client, appID uint64, version string, expectedCreator string, expectedAdmin string) (Verification, error) {
+	app, err := algodClient.GetApplicationByID(appID).Do(ctx)
+	if err != nil {
+		return Verification{}, errors.WithStack(err)
+	}
+
+	state, err := FormatState(app.Params.GlobalState)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	programs, schemas := contracts.Programs, KingOfAlgoStateSchemas
+	if state.AssetID != 0 {
+		programs, schemas = contracts.ASAPrograms, KingOfAlgoASAStateSchemas
+	}
+
+	approvalSource, clearSource, err := programs(version)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	approvalProgram, clearProgram, err := compileSources(ctx, algodClient, approvalSource, clearSource)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	gSchema, _ := schemas()
+	if version == "v1" {
+		gSchema = v1StateSchema
+	}
+
+	if expectedAdmin == "" {
+		expectedAdmin = app.Params.Creator
+	}
+
+	result := Verification{
+		AppID:         appID,
+		Version:       version,
+		Creator:       app.Params.Creator,
+		Admin:         state.Admin,
+		ApprovalMatch: bytes.Equal(app.Params.ApprovalProgram, approvalProgram),
+		ClearMatch:    bytes.Equal(app.Params.ClearStateProgram, clearProgram),
+		SchemaMatch: app.Params.GlobalStateSchema.NumUint == gSchema.NumUint &&
+			app.Params.GlobalStateSchema.NumByteSlice == gSchema.NumByteSlice,
+		CreatorMatch: expectedCreator == "" || app.Params.Creator == expectedCreator,
+		AdminMatch:   state.Admin == expectedAdmin,
+	}
+
+	if result.ApprovalMatch && result.ClearMatch {
+		result.Detected = version
+	} else {
+		result.Detected, err = detectVersion(ctx, algodClient, programs, app.Params.ApprovalProgram, app.Params.ClearStateProgram)
+		if err != nil {
+			return Verification{}, err
+		}
+	}
+
+	if !result.ApprovalMatch {
+		result.ApprovalDiff, err = disassemblyDiff(ctx, algodClient, approvalProgram, app.Params.ApprovalProgram)
+		if err != nil {
+			return Verification{}, err
+		}
+	}
+
+	if !result.ClearMatch {
+		result.ClearDiff, err = disassemblyDiff(ctx, algodClient, clearProgram, app.Params.ClearStateProgram)
+		if err != nil {
+			return Verification{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// detectVersion returns the embedded contract version whose programs are the given ones, empty when there is none.
+// The variant has no program for some versions, they are skipped.
+func detectVersion(
+	ctx context.Context,
+	algodClient *algod.Client,
+	programs func(string) ([]byte, []byte, error),
+	approvalProgram []byte,
+	clearProgram []byte,
+) (string, error) {
+	for _, version := range contracts.Versions {
+		approvalSource, clearSource, err := programs(version)
+		if err != nil {
+			continue
+		}
+
+		versionApprovalProgram, versionClearProgram, err := compileSources(ctx, algodClient, approvalSource, clearSource)
+		if err != nil {
+			return "", err
+		}
+
+		if bytes.Equal(approvalProgram, versionApprovalProgram) && bytes.Equal(clearProgram, versionClearProgram) {
+			return version, nil
+		}
+	}
+
+	return "", nil
+}
+
+// disassemblyDiff returns a line diff between the disassembly of the expected and the on-chain program.
+func disassemblyDiff(ctx context.Context, algodClient *algod.Client, expected []byte, actual []byte) (string, error) {
+	expectedSource, err := algodClient.TealDisassemble(expected).Do(ctx)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	actualSource, err := algodClient.TealDisassemble(actual).Do(ctx)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return diffLines(
+		strings.Split(expectedSource.Result, "\n"),
+		strings.Split(actualSource.Result, "\n"),
+	), nil
+}
+
+// diffLines returns the lines removed from a (prefixed with "-") and added in b (prefixed with "+").
+func diffLines(a []string, b []string) string {
+	// Longest common subsequence table.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,463 @@
+// Command appspecgen generates the typed Go client of the King Of Algo apps from their ARC-56 app specs:
+// a client per app spec with a wrapper per method, the deploy helpers and the decoded global state of all of them.
+//
+//	go run ./cmd/appspecgen -package client -out client/appspec_gen.go contracts/KingOfAlgo.arc56.json contracts/KingOfAlgoASA.arc56.json
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// appSpec is the part of an ARC-56 app spec the client needs.
+type appSpec struct {
+	Name    string   `json:"name"`
+	Desc    string   `json:"desc"`
+	Methods []method `json:"methods"`
+	State   struct {
+		Schema struct {
+			Global schema `json:"global"`
+			Local  schema `json:"local"`
+		} `json:"schema"`
+		Keys struct {
+			Global map[string]stateKey `json:"global"`
+		} `json:"keys"`
+	} `json:"state"`
+
+	// file is the path of the app spec, for the header of the generated file.
+	file string
+}
+
+type schema struct {
+	Ints  uint64 `json:"ints"`
+	Bytes uint64 `json:"bytes"`
+}
+
+type stateKey struct {
+	KeyType   string `json:"keyType"`
+	ValueType string `json:"valueType"`
+	Key       string `json:"key"`
+	Desc      string `json:"desc"`
+}
+
+type method struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+	Args []struct {
+		Type         string `json:"type"`
+		Name         string `json:"name"`
+		Desc         string `json:"desc"`
+		DefaultValue *struct {
+			Source string `json:"source"`
+			Data   string `json:"data"`
+			Type   string `json:"type"`
+		} `json:"defaultValue"`
+	} `json:"args"`
+	Returns struct {
+		Type string `json:"type"`
+		Desc string `json:"desc"`
+	} `json:"returns"`
+	Actions struct {
+		Create []string `json:"create"`
+		Call   []string `json:"call"`
+	} `json:"actions"`
+}
+
+// goTypes are the Go types of the ABI types the methods use.
+var goTypes = map[string]string{
+	"uint64":   "uint64",
+	"string":   "string",
+	"address":  "types.Address",
+	"byte[32]": "[32]byte",
+	"pay":      "transaction.TransactionWithSigner",
+	"axfer":    "transaction.TransactionWithSigner",
+	"txn":      "transaction.TransactionWithSigner",
+}
+
+// stateTypes are the Go types of the values of the global state keys and how they are decoded.
+var stateTypes = map[string]struct {
+	goType   string
+	tealType string
+	decode   string
+}{
+	"uint64":   {"uint64", "tealUintType", "state.%s = keyValue.Value.Uint"},
+	"address":  {"string", "tealBytesType", "state.%s, err = decodeAddress(keyValue.Value.Bytes)"},
+	"AVMBytes": {"[]byte", "tealBytesType", "state.%s, err = decodeBytes(keyValue.Value.Bytes)"},
+}
+
+func main() {
+	pkg := flag.String("package", "client", "package of the generated file")
+	out := flag.String("out", "appspec_gen.go", "generated file")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: appspecgen [flags] <app spec>...")
+		os.Exit(2)
+	}
+
+	err := generate(*pkg, *out, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(pkg string, out string, specPaths []string) error {
+	specs := []appSpec{}
+	for _, path := range specPaths {
+		specJSON, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		spec := appSpec{file: filepath.Base(path)}
+		err = json.Unmarshal(specJSON, &spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		specs = append(specs, spec)
+	}
+
+	data, err := templateData(pkg, specs)
+	if err != nil {
+		return err
+	}
+
+	source := bytes.Buffer{}
+	err = clientTemplate.Execute(&source, data)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated code doesn't parse: %w\n%s", err, source.String())
+	}
+
+	return os.WriteFile(out, formatted, 0o644)
+}
+
+type fileData struct {
+	Package string
+	Files   []string
+	Apps    []appData
+	Keys    []keyData
+}
+
+type appData struct {
+	Name string
+	// VarName is the name of the app in the unexported identifiers.
+	VarName  string
+	Desc     string
+	Global   schema
+	Local    schema
+	Create   methodData
+	Methods  []methodData
+	Defaults []defaultData
+	Keys     []keyData
+}
+
+type methodData struct {
+	Name      string
+	GoName    string
+	Desc      string
+	Signature string
+	Args      []argData
+	// Returns is the Go type of the return value, empty for a void method.
+	Returns string
+}
+
+type argData struct {
+	Name   string
+	GoType string
+	Desc   string
+}
+
+type defaultData struct {
+	GoName string
+	Desc   string
+	Value  uint64
+}
+
+type keyData struct {
+	Key      string
+	GoName   string
+	GoType   string
+	TealType string
+	Decode   string
+	Desc     string
+}
+
+func templateData(pkg string, specs []appSpec) (fileData, error) {
+	data := fileData{Package: pkg}
+	keys := map[string]keyData{}
+	for _, spec := range specs {
+		data.Files = append(data.Files, spec.file)
+
+		app := appData{
+			Name:    spec.Name,
+			VarName: strings.ToLower(spec.Name[:1]) + spec.Name[1:],
+			Desc:    spec.Desc,
+			Global:  spec.State.Schema.Global,
+			Local:   spec.State.Schema.Local,
+		}
+
+		for _, m := range spec.Methods {
+			methodData, err := makeMethodData(m)
+			if err != nil {
+				return fileData{}, fmt.Errorf("%s: %w", spec.Name, err)
+			}
+
+			if len(m.Actions.Create) == 0 {
+				app.Methods = append(app.Methods, methodData)
+				continue
+			}
+
+			if app.Create.Name != "" {
+				return fileData{}, fmt.Errorf("%s: more than one create method", spec.Name)
+			}
+
+			app.Create = methodData
+			for _, arg := range m.Args {
+				if arg.DefaultValue == nil {
+					continue
+				}
+
+				if arg.DefaultValue.Source != "literal" || arg.DefaultValue.Type != "uint64" {
+					return fileData{}, fmt.Errorf("%s: default of %s isn't a uint64 literal", spec.Name, arg.Name)
+				}
+
+				value, err := base64.StdEncoding.DecodeString(arg.DefaultValue.Data)
+				if err != nil || len(value) != 8 {
+					return fileData{}, fmt.Errorf("%s: default of %s isn't a uint64", spec.Name, arg.Name)
+				}
+
+				app.Defaults = append(app.Defaults, defaultData{
+					GoName: spec.Name + "Default" + goName(arg.Name),
+					Desc:   arg.Desc,
+					Value:  binary.BigEndian.Uint64(value),
+				})
+			}
+		}
+
+		if app.Create.Name == "" {
+			return fileData{}, fmt.Errorf("%s: no create method", spec.Name)
+		}
+
+		for name, key := range spec.State.Keys.Global {
+			keyName, err := base64.StdEncoding.DecodeString(key.Key)
+			if err != nil {
+				return fileData{}, fmt.Errorf("%s: key %s: %w", spec.Name, name, err)
+			}
+
+			stateType, ok := stateTypes[key.ValueType]
+			if !ok {
+				return fileData{}, fmt.Errorf("%s: key %s has an unsupported value type %s", spec.Name, name, key.ValueType)
+			}
+
+			k := keyData{
+				Key:      string(keyName),
+				GoName:   goName(string(keyName)),
+				GoType:   stateType.goType,
+				TealType: stateType.tealType,
+				Decode:   fmt.Sprintf(stateType.decode, goName(string(keyName))),
+				Desc:     key.Desc,
+			}
+
+			// The variants share most keys, they have to agree on them.
+			if other, ok := keys[k.Key]; ok && other.GoType != k.GoType {
+				return fileData{}, fmt.Errorf("%s: key %s is a %s in another app spec", spec.Name, k.Key, other.GoType)
+			}
+
+			keys[k.Key] = k
+			app.Keys = append(app.Keys, k)
+		}
+
+		sort.Slice(app.Keys, func(i, j int) bool { return app.Keys[i].Key < app.Keys[j].Key })
+		data.Apps = append(data.Apps, app)
+	}
+
+	for _, key := range keys {
+		data.Keys = append(data.Keys, key)
+	}
+
+	sort.Slice(data.Keys, func(i, j int) bool { return data.Keys[i].Key < data.Keys[j].Key })
+
+	return data, nil
+}
+
+func makeMethodData(m method) (methodData, error) {
+	data := methodData{Name: m.Name, GoName: goName(m.Name), Desc: m.Desc}
+
+	argTypes := []string{}
+	for _, arg := range m.Args {
+		goType, ok := goTypes[arg.Type]
+		if !ok {
+			return methodData{}, fmt.Errorf("method %s: unsupported arg type %s", m.Name, arg.Type)
+		}
+
+		argTypes = append(argTypes, arg.Type)
+		data.Args = append(data.Args, argData{Name: argName(arg.Name), GoType: goType, Desc: arg.Desc})
+	}
+
+	switch m.Returns.Type {
+	case "void":
+	case "uint64":
+		data.Returns = "uint64"
+	default:
+		return methodData{}, fmt.Errorf("method %s: unsupported return type %s", m.Name, m.Returns.Type)
+	}
+
+	data.Signature = fmt.Sprintf("%s(%s)%s", m.Name, strings.Join(argTypes, ","), m.Returns.Type)
+
+	return data, nil
+}
+
+// goName turns a snake case name into an exported Go name.
+func goName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if part == "id" {
+			parts[i] = "ID"
+			continue
+		}
+
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+// argName turns a snake case name into an unexported Go name.
+func argName(name string) string {
+	exported := goName(name)
+
+	return strings.ToLower(exported[:1]) + exported[1:]
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by appspecgen from {{range $i, $f := .Files}}{{if $i}}, {{end}}{{$f}}{{end}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/pkg/errors"
+)
+
+{{range $app := .Apps}}
+{{- range .Defaults}}
+// {{.GoName}} is the default of the create arg: {{.Desc}}.
+const {{.GoName}} uint64 = {{.Value}}
+{{end}}
+// {{.Name}}StateSchemas returns the global and local schemas of the {{.Name}} app.
+func {{.Name}}StateSchemas() (types.StateSchema, types.StateSchema) {
+	return types.StateSchema{NumUint: {{.Global.Ints}}, NumByteSlice: {{.Global.Bytes}}},
+		types.StateSchema{NumUint: {{.Local.Ints}}, NumByteSlice: {{.Local.Bytes}}}
+}
+
+// {{.Name}}CreateArgs encodes the app args of the {{.Create.Name}} call creating the {{.Name}} app.
+{{- range .Create.Args}}
+// {{.Name}}: {{.Desc}}.
+{{- end}}
+func {{.Name}}CreateArgs({{range $i, $a := .Create.Args}}{{if $i}}, {{end}}{{$a.Name}} {{$a.GoType}}{{end}}) ([][]byte, error) {
+	return encodeMethodArgs("{{.Create.Signature}}"{{range .Create.Args}}, {{.Name}}{{end}})
+}
+
+// {{.Name}}Client calls the methods of a {{.Name}} app: {{.Desc}}
+type {{.Name}}Client struct {
+	appClient
+}
+
+// New{{.Name}}Client returns the client of the {{.Name}} app with the given ID.
+func New{{.Name}}Client(algodClient *algod.Client, appID uint64) {{.Name}}Client {
+	return {{.Name}}Client{appClient{algod: algodClient, appID: appID}}
+}
+{{range .Methods}}
+// {{.GoName}} calls {{.Signature}}: {{.Desc}}
+{{- range .Args}}
+// {{.Name}}: {{.Desc}}.
+{{- end}}
+func (c {{$app.Name}}Client) {{.GoName}}(ctx context.Context, sender Account, params CallParams{{range .Args}}, {{.Name}} {{.GoType}}{{end}}) ({{if .Returns}}{{.Returns}}, {{end}}MethodResult, error) {
+{{- if .Returns}}
+	result, err := c.call(ctx, sender, params, "{{.Signature}}"{{range .Args}}, {{.Name}}{{end}})
+	if err != nil {
+		return 0, result, err
+	}
+
+	value, ok := result.ReturnValue.({{.Returns}})
+	if !ok {
+		return 0, result, errors.Errorf("{{.Name}} of app %d returned %T", c.appID, result.ReturnValue)
+	}
+
+	return value, result, nil
+{{- else}}
+	return c.call(ctx, sender, params, "{{.Signature}}"{{range .Args}}, {{.Name}}{{end}})
+{{- end}}
+}
+
+// Add{{.GoName}} adds the call of {{.Signature}} to the composer, after the txs of its args.
+func (c {{$app.Name}}Client) Add{{.GoName}}(atc *transaction.AtomicTransactionComposer, sender Account, suggestedParams types.SuggestedParams, params CallParams{{range .Args}}, {{.Name}} {{.GoType}}{{end}}) error {
+	return c.add(atc, sender, suggestedParams, params, "{{.Signature}}"{{range .Args}}, {{.Name}}{{end}})
+}
+{{end}}
+{{- end}}
+// AppGlobalState is the global state of the apps as their app specs describe it, a key the variant doesn't have keeps its zero value.
+type AppGlobalState struct {
+{{- range .Keys}}
+	// {{.GoName}} is the {{.Key}} key: {{.Desc}}.
+	{{.GoName}} {{.GoType}}
+{{- end}}
+	// Raw holds the global state as read, keyed by the decoded key.
+	Raw map[string]models.TealValue
+}
+
+{{range .Apps}}
+// {{.VarName}}GlobalStateTypes are the TEAL types of the global state keys of the {{.Name}} app.
+var {{.VarName}}GlobalStateTypes = map[string]uint64{
+{{- range .Keys}}
+	"{{.Key}}": {{.TealType}},
+{{- end}}
+}
+{{end}}
+// DecodeAppGlobalState decodes the global state read from algod.
+func DecodeAppGlobalState(rawState []models.TealKeyValue) (AppGlobalState, error) {
+	state := AppGlobalState{Raw: map[string]models.TealValue{}}
+	for _, keyValue := range rawState {
+		key, err := base64.StdEncoding.DecodeString(keyValue.Key)
+		if err != nil {
+			return AppGlobalState{}, errors.WithStack(err)
+		}
+
+		state.Raw[string(key)] = keyValue.Value
+
+		switch string(key) {
+{{- range .Keys}}
+		case "{{.Key}}":
+			{{.Decode}}
+{{- end}}
+		}
+		if err != nil {
+			return AppGlobalState{}, errors.Wrapf(err, "global state key %q", key)
+		}
+	}
+
+	return state, nil
+}
+`))
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func abdicateCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("abdicate", flag.ExitOnError)
+	accountName := flags.String("account", "", "account of the king, defaults to the player account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	flags.Parse(args)
+
+	account, err := cfg.AccountFor(*accountName, client.RolePlayer)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	compensation, err := client.Abdicate(ctx, algodClient, account, *appID, 5)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s abdicated and collected a compensation of %d\n", account.Address, compensation)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func adminCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("admin", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: koa admin [flags] propose <address> | accept | cancel")
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "admin account, or the pending admin account to accept, defaults to the admin account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	flags.Parse(args)
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "propose":
+		if flags.Arg(1) == "" {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		err = client.ProposeAdmin(ctx, algodClient, account, *appID, flags.Arg(1), 5)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s proposed as admin of app %d, it has to accept\n", flags.Arg(1), *appID)
+
+		return nil
+	case "accept":
+		err = client.AcceptAdmin(ctx, algodClient, account, *appID, 5)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is the admin of app %d\n", account.Address, *appID)
+
+		return nil
+	case "cancel":
+		return client.CancelAdminProposal(ctx, algodClient, account, *appID, 5)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func claimCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("claim", flag.ExitOnError)
+	accountName := flags.String("account", "", "account claiming the throne, defaults to the player account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	message := flags.String("message", "", "message of the new king")
+	debug := flags.Bool("debug", false, "dry run the group before sending it")
+	crown := flags.Bool("crown", true, "collect the crown of the new king, opting in to it")
+	feeMode := flags.String("fee-mode", string(cfg.Fees.Mode), "fee strategy: minimum, suggested, congestion or pooled")
+	feeMultiplier := flags.Uint64("fee-multiplier", cfg.Fees.Multiplier, "multiplier of the suggested fee in the congestion mode")
+	maxFee := flags.Uint64("max-fee", cfg.Fees.MaxFee, "max fees of the claim group in microalgos, inner txs included")
+	flags.Parse(args)
+
+	account, err := cfg.AccountFor(*accountName, client.RolePlayer)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	// The outcome of a claim sent before a crash tells whether the account is already king.
+	err = reconcileJournal(ctx, cfg, algodClient)
+	if err != nil {
+		return err
+	}
+
+	state, err := client.GetAppState(ctx, algodClient, *appID)
+	if err != nil {
+		return err
+	}
+
+	suggestedParams, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	params := client.NewBecomeKingParams(suggestedParams, *appID, state, account, *message).WithFees(client.FeeStrategy{
+		Mode:       client.FeeMode(*feeMode),
+		Multiplier: *feeMultiplier,
+		MaxFee:     *maxFee,
+	})
+	if !*crown {
+		params = params.LeaveCrown()
+	}
+
+	resp, err := client.BecomeKing(ctx, algodClient, *debug, params, 5)
+	if err != nil && resp.ConfirmedRound == 0 {
+		return err
+	}
+
+	fmt.Printf("%s is the king since round %d\n", account.Address, resp.ConfirmedRound)
+	fmt.Printf("fees: %s\n", resp.Fees)
+	if resp.Crown != 0 {
+		fmt.Printf("crown: %d\n", resp.Crown)
+	}
+
+	// The claim is confirmed when only collecting the crown failed, koa crowns collect retries it.
+	return err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func crownsCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("crowns", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: koa crowns [flags] [collect]")
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "account holding the crowns, defaults to the player account")
+	address := flags.String("address", "", "address holding the crowns, instead of an account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID the crowns are collected from")
+	factoryID := flags.Uint64("factory", cfg.Profile().FactoryID, "factory whose thrones award crowns, with the app and the apps of the registry")
+	flags.Parse(args)
+
+	if flags.Arg(0) == "collect" {
+		return collectCrowns(ctx, cfg, *accountName, *appID)
+	}
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if *address == "" {
+		account, err := cfg.AccountFor(*accountName, client.RolePlayer)
+		if err != nil {
+			return err
+		}
+
+		*address = account.Address.String()
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	indexerClient, err := client.MakeIndexerClient(cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	registry, err := client.LoadRegistry(cfg.Registry)
+	if err != nil {
+		return err
+	}
+
+	factoryIDs := []uint64{}
+	if *factoryID != 0 {
+		factoryIDs = append(factoryIDs, *factoryID)
+	}
+
+	appIDs, err := client.KnownThrones(ctx, algodClient, registry, factoryIDs...)
+	if err != nil {
+		return err
+	}
+
+	if *appID != 0 {
+		appIDs = append(appIDs, *appID)
+	}
+
+	crowns, err := client.ListCrowns(ctx, algodClient, indexerClient, *address, appIDs)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CROWN\tAPP\tREIGN\tPRICE\tMESSAGE")
+	for _, crown := range crowns {
+		if crown.Err != nil {
+			fmt.Fprintf(w, "%d\t%d\terror: %v\t\t\n", crown.AssetID, crown.AppID, crown.Err)
+			continue
+		}
+
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%q\n", crown.AssetID, crown.AppID, crown.Reign, crown.Price, crown.Message)
+	}
+
+	return w.Flush()
+}
+
+// collectCrowns collects the crowns the app minted for the account and still holds.
+func collectCrowns(ctx context.Context, cfg *client.Config, accountName string, appID uint64) error {
+	account, err := cfg.AccountFor(accountName, client.RolePlayer)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	crownIDs, err := client.UncollectedCrowns(ctx, algodClient, appID, account.Address.String())
+	if err != nil {
+		return err
+	}
+
+	for _, crownID := range crownIDs {
+		err = client.CollectCrown(ctx, algodClient, account, appID, crownID, 5)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("crown %d collected\n", crownID)
+	}
+
+	if len(crownIDs) == 0 {
+		fmt.Printf("app %d holds no crown of %s\n", appID, account.Address)
+	}
+
+	return nil
+}
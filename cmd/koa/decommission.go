@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func decommissionCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("decommission", flag.ExitOnError)
+	accountName := flags.String("account", "", "admin account, defaults to the admin account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	force := flags.Bool("force", false, "delete even if the reign is active")
+	dryRun := flags.Bool("dry-run", false, "only show what the app account holds")
+	flags.Parse(args)
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	report, err := client.InspectDecommission(ctx, algodClient, *appID)
+	if err != nil {
+		return err
+	}
+
+	printDecommissionReport(report)
+
+	if *dryRun {
+		return nil
+	}
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Decommission(ctx, algodClient, account, *appID, *force, 5)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("app %d deleted\n", *appID)
+
+	return nil
+}
+
+func printDecommissionReport(report client.DecommissionReport) {
+	fmt.Printf("app:          %d (%s)\n", report.AppID, report.AppAddress)
+	fmt.Printf("admin:        %s\n", report.Admin)
+	fmt.Printf("king:         %s\n", report.King)
+	fmt.Printf("end of reign: %s\n", report.EndOfReign)
+	fmt.Printf("balance:      %d\n", report.Balance)
+	if report.AssetID != 0 {
+		fmt.Printf("compensation: %d of asset %d to the king\n", report.Compensation, report.AssetID)
+		fmt.Printf("remainder:    %d and %d of asset %d to the admin\n", report.Remainder, report.AssetRemainder, report.AssetID)
+	} else {
+		fmt.Printf("compensation: %d to the king\n", report.Compensation)
+		fmt.Printf("remainder:    %d to the admin\n", report.Remainder)
+	}
+	if len(report.Uncollected) > 0 {
+		fmt.Printf("uncollected:  %d crowns burned first, their min balance to the admin\n", len(report.Uncollected))
+	}
+	if report.Locked > 0 {
+		fmt.Printf("locked:       %d for %d crowns the kings collected\n", report.Locked, report.Crowns-uint64(len(report.Uncollected)))
+	}
+	if report.Upgrade != nil {
+		fmt.Printf("announced:    %s\n", report.Upgrade)
+	} else {
+		fmt.Printf("announced:    nothing, announce the delete first\n")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func deployCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	accountName := flags.String("account", "", "account creating the app, defaults to the admin account")
+	name := flags.String("name", "", "name of the app in the registry, the app isn't registered without one")
+	period := flags.Duration("period", cfg.ReignPeriod, "reign period")
+	upgradeDelay := flags.Duration("upgrade-delay", client.DefaultUpgradeDelay, "delay between the announce of an upgrade or delete and its execution")
+	note := flags.String("note", "", "creation note")
+	assetID := flags.Uint64("asset", 0, "ASA the throne is priced in, ALGO when 0")
+	initPrice := flags.Uint64("init-price", 0, "init price in base units of the asset, required with -asset")
+	flags.Parse(args)
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	err = reconcileJournal(ctx, cfg, algodClient)
+	if err != nil {
+		return err
+	}
+
+	registry, err := client.LoadRegistry(cfg.Registry)
+	if err != nil {
+		return err
+	}
+
+	var appID uint64
+	if *assetID != 0 {
+		appID, err = client.DeployASA(ctx, algodClient, account, registry, *name, *period, *upgradeDelay, *assetID, *initPrice, *note)
+	} else {
+		appID, err = client.Deploy(ctx, algodClient, account, registry, *name, *period, *upgradeDelay, *note)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("app %d created by %s (%s)\n", appID, account.Name, account.Address)
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/doctor"
+)
+
+func doctorCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	accountName := flags.String("account", "", "account to check, defaults to the player account")
+	jsonReport := flags.Bool("json", false, "print the report as JSON")
+	flags.Parse(args)
+
+	report := doctor.Run(ctx, cfg, *accountName)
+
+	var err error
+	if *jsonReport {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !report.OK() {
+		return errors.New("some checks failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+func factoryCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("factory", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: koa factory [flags] deploy | create | announce-retire <app-id> | retire <app-id> | burn-crowns <app-id> |")
+		fmt.Fprintln(os.Stderr, "       hand-over <app-id> <address> | withdraw | announce-upgrade | execute-upgrade | cancel-upgrade")
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "admin account, defaults to the admin account")
+	factoryID := flags.Uint64("factory", cfg.Profile().FactoryID, "factory app ID")
+	period := flags.Duration("period", cfg.ReignPeriod, "reign period of the created throne")
+	upgradeDelay := flags.Duration("upgrade-delay", client.DefaultUpgradeDelay, "upgrade delay of the deployed factory or the created throne")
+	force := flags.Bool("force", false, "retire even if the reign is active")
+	version := flags.String("version", contracts.Version, "contract version of the factory to upgrade to")
+	flags.Parse(args)
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "deploy":
+		id, err := client.DeployFactory(ctx, algodClient, account, contracts.Version, *upgradeDelay)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("factory %d created by %s (%s), set factoryid in the profile\n", id, account.Name, account.Address)
+
+		return nil
+	case "create":
+		appID, err := client.CreateThrone(ctx, algodClient, account, *factoryID, *period, *upgradeDelay)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("throne %d created by factory %d\n", appID, *factoryID)
+
+		return nil
+	case "announce-retire":
+		appID, err := strconv.ParseUint(flags.Arg(1), 10, 64)
+		if err != nil {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		upgrade, err := client.AnnounceRetire(ctx, algodClient, account, *factoryID, appID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("throne %d can be retired from %s\n", appID, upgrade.At.UTC().Format(time.RFC3339))
+
+		return nil
+	case "retire":
+		appID, err := strconv.ParseUint(flags.Arg(1), 10, 64)
+		if err != nil {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		report, err := client.RetireThrone(ctx, algodClient, account, *factoryID, appID, *force)
+		printDecommissionReport(report)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("throne %d retired\n", appID)
+
+		return nil
+	case "burn-crowns":
+		appID, err := strconv.ParseUint(flags.Arg(1), 10, 64)
+		if err != nil {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		crownIDs, err := client.BurnThroneCrowns(ctx, algodClient, account, *factoryID, appID)
+		for _, crownID := range crownIDs {
+			fmt.Printf("crown %d burned\n", crownID)
+		}
+
+		return err
+	case "hand-over":
+		appID, err := strconv.ParseUint(flags.Arg(1), 10, 64)
+		if err != nil || flags.Arg(2) == "" {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		err = client.HandOverThrone(ctx, algodClient, account, *factoryID, appID, flags.Arg(2))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("throne %d left factory %d, %s accepts the admin with koa admin accept\n", appID, *factoryID, flags.Arg(2))
+
+		return nil
+	case "withdraw":
+		return client.WithdrawFactory(ctx, algodClient, account, *factoryID)
+	case "announce-upgrade", "execute-upgrade":
+		approvalSource, clearSource, err := contracts.FactoryPrograms(*version)
+		if err != nil {
+			return err
+		}
+
+		approvalProgram, clearProgram, hash, err := client.CompileUpgrade(ctx, algodClient, approvalSource, clearSource)
+		if err != nil {
+			return err
+		}
+
+		if flags.Arg(0) == "execute-upgrade" {
+			err = client.ExecuteFactoryUpgrade(ctx, algodClient, account, *factoryID, approvalProgram, clearProgram)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("factory %d upgraded to %s\n", *factoryID, hex.EncodeToString(hash))
+
+			return nil
+		}
+
+		upgrade, err := client.AnnounceFactoryUpgrade(ctx, algodClient, account, *factoryID, approvalProgram, clearProgram)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("factory %d: %s\n", *factoryID, upgrade)
+
+		return nil
+	case "cancel-upgrade":
+		err = client.CancelFactoryUpgrade(ctx, algodClient, account, *factoryID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("factory %d: upgrade cancelled\n", *factoryID)
+
+		return nil
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+func lobbyCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("lobby", flag.ExitOnError)
+	factoryID := flags.Uint64("factory", cfg.Profile().FactoryID, "factory app ID")
+	flags.Parse(args)
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	thrones, err := client.ListThrones(ctx, algodClient, *factoryID, client.StatesOptions{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tKING\tCLAIM PRICE\tEND OF REIGN\tLEFT")
+	for _, throne := range thrones {
+		if throne.Err != nil {
+			fmt.Fprintf(w, "%d\terror: %v\t\t\t\n", throne.AppID, throne.Err)
+			continue
+		}
+
+		king := throne.State.King
+		if king == "" {
+			king = "-"
+		}
+
+		left := "ended"
+		if throne.State.Paused {
+			left = "paused"
+		} else if !throne.State.IsReignEnded() {
+			left = time.Until(throne.State.EndOfReign).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", throne.AppID, king, throne.State.ClaimPrice(), throne.State.EndOfReign.UTC().Format(time.RFC3339), left)
+	}
+
+	return w.Flush()
+}
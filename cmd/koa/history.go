@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func historyCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	round := flags.Uint64("round", 0, "show the state at this round")
+	from := flags.String("from", "", "show the changes since this time (RFC 3339)")
+	to := flags.String("to", "", "show the changes until this time (RFC 3339), defaults to now")
+	store := flags.String("store", "", "read the deltas from this local event store instead of the indexer")
+	sync := flags.Bool("sync", false, "copy the deltas from the indexer to the local event store first")
+	flags.Parse(args)
+
+	var source client.EventSource
+	if *store == "" || *sync {
+		indexerClient, err := client.MakeIndexerClient(cfg.Profile())
+		if err != nil {
+			return err
+		}
+
+		source = client.IndexerSource{Indexer: indexerClient}
+	}
+
+	if *store != "" {
+		eventStore := client.NewFileEventStore(*store)
+		if *sync {
+			err := eventStore.Sync(ctx, source, *appID)
+			if err != nil {
+				return err
+			}
+		}
+
+		source = eventStore
+	}
+
+	if *round > 0 {
+		state, err := client.StateAt(ctx, source, *appID, *round)
+		if err != nil {
+			return err
+		}
+
+		printState(state)
+
+		return nil
+	}
+
+	fromTime := time.Time{}
+	toTime := time.Now()
+	var err error
+	if *from != "" {
+		fromTime, err = time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *to != "" {
+		toTime, err = time.Parse(time.RFC3339, *to)
+		if err != nil {
+			return err
+		}
+	}
+
+	changes, err := client.StateChangesBetween(ctx, source, *appID, fromTime, toTime)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		fmt.Printf("round %d at %s, tx %s by %s\n", change.Round, change.Time.Format(time.RFC3339), change.TxID, change.Sender)
+		printState(change.State)
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func printState(state client.State) {
+	fmt.Printf("  king:         %s\n", state.King)
+	fmt.Printf("  king price:   %d\n", state.KingPrice)
+	fmt.Printf("  init price:   %d\n", state.InitPrice)
+	fmt.Printf("  end of reign: %s\n", state.EndOfReign.UTC().Format(time.RFC3339))
+	fmt.Printf("  admin:        %s\n", state.Admin)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func journalCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("journal", flag.ExitOnError)
+	all := flags.Bool("all", false, "list the settled groups too")
+	flags.Parse(args)
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	err = reconcileJournal(ctx, cfg, algodClient)
+	if err != nil {
+		return err
+	}
+
+	entries, err := client.NewJournal(cfg.Journal).Entries()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if *all || entry.Status == client.JournalPending {
+			printJournalEntry(entry)
+		}
+	}
+
+	return nil
+}
+
+// reconcileJournal settles the groups sent before the process stopped and prints their outcome,
+// then finishes the deploys whose app was created but not funded or registered.
+func reconcileJournal(ctx context.Context, cfg *client.Config, algodClient *algod.Client) error {
+	journal := client.NewJournal(cfg.Journal)
+	reconciled, reconcileErr := journal.Reconcile(ctx, algodClient, client.DefaultSubmitOptions)
+	for _, entry := range reconciled {
+		printJournalEntry(entry)
+	}
+
+	// The groups still pending don't hold back the deploys already confirmed.
+	incomplete, err := journal.Incomplete()
+	if err != nil {
+		return err
+	}
+
+	if len(incomplete) == 0 {
+		return reconcileErr
+	}
+
+	registry, err := client.LoadRegistry(cfg.Registry)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range incomplete {
+		account, err := cfg.LoadAccount(entry.Deploy.Account)
+		if err == nil {
+			err = client.CompleteDeploy(client.WithJournal(ctx, journal), algodClient, account, registry, entry)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "deploy %q of app %d is incomplete", entry.Name, entry.AppID)
+		}
+
+		fmt.Printf("deploy %q of app %d completed\n", entry.Name, entry.AppID)
+	}
+
+	return reconcileErr
+}
+
+func printJournalEntry(entry client.JournalEntry) {
+	switch entry.Kind {
+	case client.JournalDeploy:
+		fmt.Printf("deploy %q by %s: %s", entry.Name, entry.Sender, entry.Status)
+	default:
+		fmt.Printf("claim of reign %d of app %d by %s for %d: %s", entry.Reign, entry.AppID, entry.Sender, entry.Price, entry.Status)
+	}
+
+	switch entry.Status {
+	case client.JournalConfirmed:
+		if entry.Kind == client.JournalDeploy {
+			fmt.Printf(" app %d", entry.AppID)
+			if !entry.Done {
+				fmt.Print(" incomplete")
+			}
+		}
+		fmt.Printf(" at round %d\n", entry.Round)
+	case client.JournalPending:
+		fmt.Printf(" until round %d (group %s)\n", entry.LastValid, entry.GroupID)
+	default:
+		fmt.Printf(" (%s)\n", entry.Error)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/client"
+	"golang.org/x/term"
+)
+
+func keysCmd(_ context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("keys", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: koa keys list | import <name> | export <name>")
+	}
+	flags.Parse(args)
+
+	keystore, err := client.OpenKeystore(cfg.Keystore.Path)
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "list":
+		for _, entry := range keystore.List() {
+			fmt.Printf("%s\t%s\n", entry.Name, entry.Address)
+		}
+
+		return nil
+	case "import":
+		if flags.Arg(1) == "" {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		// The mnemonic is read from stdin so it doesn't end up in the shell history. The passphrase prompt reads
+		// from the same reader, so a piped mnemonic and passphrase aren't lost in its buffer.
+		stdin := bufio.NewReader(os.Stdin)
+		words, err := readSecret(stdin, "Mnemonic: ")
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := newPassphrase(cfg, stdin)
+		if err != nil {
+			return err
+		}
+
+		return keystore.ImportMnemonic(flags.Arg(1), string(words), passphrase)
+	case "export":
+		if flags.Arg(1) == "" {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		passphrase, err := passphrase(cfg)
+		if err != nil {
+			return err
+		}
+
+		words, err := keystore.Export(flags.Arg(1), passphrase)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(words)
+
+		return nil
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+// newPassphrase asks for the passphrase of an imported key twice when it is prompted, a typo would lock the key.
+func newPassphrase(cfg *client.Config, stdin *bufio.Reader) ([]byte, error) {
+	if cfg.Keystore.Passphrase != "prompt" {
+		return passphrase(cfg)
+	}
+
+	passphrase, err := readSecret(stdin, "Keystore passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	confirmation, err := readSecret(stdin, "Confirm the passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(passphrase, confirmation) {
+		return nil, errors.New("the passphrases don't match")
+	}
+
+	return passphrase, nil
+}
+
+// readSecret reads a line without echoing it on a terminal, or from the reader when stdin is piped.
+func readSecret(stdin *bufio.Reader, prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		defer fmt.Fprintln(os.Stderr)
+
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return secret, nil
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return nil, errors.WithStack(err)
+	}
+
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
+
+func passphrase(cfg *client.Config) ([]byte, error) {
+	source, err := client.ParsePassphrase(cfg.Keystore.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return source()
+}
@@ -0,0 +1,109 @@
+// Command koa is the command line client of King of Algo.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/qrksp/king-of-algo/client"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type command func(ctx context.Context, cfg *client.Config, args []string) error
+
+var commands = map[string]command{
+	"abdicate":     abdicateCmd,
+	"admin":        adminCmd,
+	"claim":        claimCmd,
+	"crowns":       crownsCmd,
+	"decommission": decommissionCmd,
+	"deploy":       deployCmd,
+	"doctor":       doctorCmd,
+	"factory":      factoryCmd,
+	"history":      historyCmd,
+	"journal":      journalCmd,
+	"keys":         keysCmd,
+	"lobby":        lobbyCmd,
+	"params":       paramsCmd,
+	"pause":        pauseCmd,
+	"unpause":      unpauseCmd,
+	"upgrade":      upgradeCmd,
+	"verify":       verifyCmd,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := client.NewConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(1)
+	}
+
+	// Every operation checks the node is on the network of the profile before signing.
+	ctx := client.WithProfile(context.Background(), cfg.Profile())
+	ctx = client.WithJournal(ctx, client.NewJournal(cfg.Journal))
+
+	var level slog.Level
+	err = level.UnmarshalText([]byte(cfg.LogLevel))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log level: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx = client.WithLogger(ctx, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Endpoint != "" {
+		tracerProvider, err = client.NewOTLPTracerProvider(ctx, cfg.Tracing.Endpoint)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%+v\n", err)
+			os.Exit(1)
+		}
+
+		otel.SetTracerProvider(tracerProvider)
+	}
+
+	err = cmd(ctx, cfg, os.Args[2:])
+
+	// The spans are flushed before exiting, whatever the outcome of the command.
+	if tracerProvider != nil {
+		shutdownErr := tracerProvider.Shutdown(context.Background())
+		if shutdownErr != nil {
+			fmt.Fprintf(os.Stderr, "exporting the spans: %v\n", shutdownErr)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: koa <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", name)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func paramsCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("params", flag.ExitOnError)
+	accountName := flags.String("account", "", "admin account, defaults to the admin account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	adminFee := flags.Uint64("admin-fee", 0, "admin fee of the next reign in percent, defaults to the current one")
+	rewardMultiplier := flags.Uint64("reward-multiplier", 0, "reward multiplier of the next reign in percent, defaults to the current one")
+	reignPeriod := flags.Duration("reign-period", 0, "reign period of the next reign, defaults to the current one")
+	factoryID := flags.Uint64("factory", 0, "factory the app belongs to, it calls the app for its admin")
+	dryRun := flags.Bool("dry-run", false, "only show the changes")
+	flags.Parse(args)
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	state, err := client.GetAppState(ctx, algodClient, *appID)
+	if err != nil {
+		return err
+	}
+
+	// The params that aren't set keep their current value.
+	params := state.CurrentParams()
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "admin-fee":
+			params.AdminFee = *adminFee
+		case "reward-multiplier":
+			params.RewardMultiplier = *rewardMultiplier
+		case "reign-period":
+			params.ReignPeriod = *reignPeriod
+		}
+	})
+
+	state, changes, err := client.PlanParams(ctx, algodClient, *appID, params)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("no change")
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+
+	if state.PendingParams != nil {
+		fmt.Printf("replaces the pending params: admin fee %d%%, reward multiplier %d%%, reign period %s\n",
+			state.PendingParams.AdminFee, state.PendingParams.RewardMultiplier, state.PendingParams.ReignPeriod)
+	}
+
+	if state.King == "" {
+		fmt.Println("applies now, there is no king")
+	} else {
+		fmt.Printf("applies when the reign ends, at %s or when the king abdicates\n", state.EndOfReign.UTC().Format(time.RFC3339))
+	}
+
+	if *dryRun {
+		return nil
+	}
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	if *factoryID != 0 {
+		return client.SetThroneParams(ctx, algodClient, account, *factoryID, *appID, params)
+	}
+
+	return client.SetParams(ctx, algodClient, account, *appID, params, 5)
+}
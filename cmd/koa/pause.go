@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/qrksp/king-of-algo/client"
+)
+
+func pauseCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	return setPaused(ctx, cfg, "pause", args)
+}
+
+func unpauseCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	return setPaused(ctx, cfg, "unpause", args)
+}
+
+func setPaused(ctx context.Context, cfg *client.Config, name string, args []string) error {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	accountName := flags.String("account", "", "admin account, defaults to the admin account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	factoryID := flags.Uint64("factory", 0, "factory the app belongs to, it calls the app for its admin")
+	flags.Parse(args)
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *factoryID != 0 && name == "pause":
+		err = client.PauseThrone(ctx, algodClient, account, *factoryID, *appID)
+	case *factoryID != 0:
+		err = client.UnpauseThrone(ctx, algodClient, account, *factoryID, *appID)
+	case name == "pause":
+		err = client.Pause(ctx, algodClient, account, *appID, 5)
+	default:
+		err = client.Unpause(ctx, algodClient, account, *appID, 5)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("app %d %sd\n", *appID, name)
+
+	return nil
+}
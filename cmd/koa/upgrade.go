@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+func upgradeCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: koa upgrade [flags] announce | execute | cancel | announce-delete | pending [app-id...]")
+		flags.PrintDefaults()
+	}
+	accountName := flags.String("account", "", "admin account, defaults to the admin account")
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID")
+	version := flags.String("version", contracts.Version, "contract version to upgrade to")
+	approvalPath := flags.String("approval", "", "TEAL approval program to upgrade to, defaults to the one of the version")
+	clearPath := flags.String("clear", "", "TEAL clear program to upgrade to, defaults to the one of the version")
+	flags.Parse(args)
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	// Watching doesn't need an account.
+	if flags.Arg(0) == "pending" {
+		appIDs := []uint64{*appID}
+		if flags.NArg() > 1 {
+			appIDs = []uint64{}
+			for _, arg := range flags.Args()[1:] {
+				id, err := strconv.ParseUint(arg, 10, 64)
+				if err != nil {
+					flags.Usage()
+					os.Exit(2)
+				}
+
+				appIDs = append(appIDs, id)
+			}
+		}
+
+		return printPendingUpgrades(client.ListPendingUpgrades(ctx, algodClient, appIDs, client.StatesOptions{}))
+	}
+
+	account, err := cfg.AccountFor(*accountName, client.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "announce", "execute":
+		approvalProgram, clearProgram, hash, err := compileUpgrade(ctx, algodClient, *appID, *version, *approvalPath, *clearPath)
+		if err != nil {
+			return err
+		}
+
+		if flags.Arg(0) == "execute" {
+			err = client.ExecuteUpgrade(ctx, algodClient, account, *appID, approvalProgram, clearProgram, 5)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("app %d upgraded to %s\n", *appID, hex.EncodeToString(hash))
+
+			return nil
+		}
+
+		upgrade, err := client.AnnounceUpgrade(ctx, algodClient, account, *appID, approvalProgram, clearProgram, 5)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("app %d: %s\n", *appID, upgrade)
+	case "announce-delete":
+		upgrade, err := client.AnnounceDelete(ctx, algodClient, account, *appID, 5)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("app %d: %s\n", *appID, upgrade)
+	case "cancel":
+		err = client.CancelUpgrade(ctx, algodClient, account, *appID, 5)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("app %d: upgrade cancelled\n", *appID)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+// compileUpgrade compiles the given TEAL files, or the programs of the version for the variant of the app.
+func compileUpgrade(ctx context.Context, algodClient *algod.Client, appID uint64, version string, approvalPath string, clearPath string) ([]byte, []byte, []byte, error) {
+	state, err := client.GetAppState(ctx, algodClient, appID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	programs := contracts.Programs
+	if state.AssetID != 0 {
+		programs = contracts.ASAPrograms
+	}
+
+	approvalSource, clearSource, err := programs(version)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if approvalPath != "" {
+		approvalSource, err = os.ReadFile(approvalPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if clearPath != "" {
+		clearSource, err = os.ReadFile(clearPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return client.CompileUpgrade(ctx, algodClient, approvalSource, clearSource)
+}
+
+func printPendingUpgrades(upgrades []client.AppUpgrade) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tANNOUNCED\tAT\tLEFT")
+	for _, upgrade := range upgrades {
+		if upgrade.Err != nil {
+			fmt.Fprintf(w, "%d\terror: %v\t\t\n", upgrade.AppID, upgrade.Err)
+			continue
+		}
+
+		announced := "delete"
+		if !upgrade.Upgrade.Delete {
+			announced = hex.EncodeToString(upgrade.Upgrade.Hash)
+		}
+
+		left := "ready"
+		if !upgrade.Upgrade.Ready() {
+			left = time.Until(upgrade.Upgrade.At).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", upgrade.AppID, announced, upgrade.Upgrade.At.UTC().Format(time.RFC3339), left)
+	}
+
+	return w.Flush()
+}
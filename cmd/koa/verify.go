@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+func verifyCmd(ctx context.Context, cfg *client.Config, args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	appID := flags.Uint64("app", cfg.Profile().APPID, "app ID to verify")
+	version := flags.String("version", contracts.Version, "contract version to compare against")
+	creator := flags.String("creator", "", "expected creator address")
+	admin := flags.String("admin", "", "expected admin address, the creator when empty")
+	flags.Parse(args)
+
+	algodClient, err := client.NewAlgodClient(ctx, cfg.Profile())
+	if err != nil {
+		return err
+	}
+
+	result, err := client.VerifyApp(ctx, algodClient, *appID, *version, *creator, *admin)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("app:      %d\n", result.AppID)
+	fmt.Printf("version:  %s\n", result.Version)
+	if result.Detected != result.Version {
+		detected := result.Detected
+		if detected == "" {
+			detected = "none of the known versions"
+		}
+
+		fmt.Printf("runs:     %s\n", detected)
+	}
+	fmt.Printf("creator:  %s %s\n", result.Creator, mark(result.CreatorMatch))
+	fmt.Printf("admin:    %s %s\n", result.Admin, mark(result.AdminMatch))
+	fmt.Printf("approval: %s\n", mark(result.ApprovalMatch))
+	fmt.Printf("clear:    %s\n", mark(result.ClearMatch))
+	fmt.Printf("schema:   %s\n", mark(result.SchemaMatch))
+
+	if result.ApprovalDiff != "" {
+		fmt.Printf("\napproval program diff (-local +on-chain):\n%s", result.ApprovalDiff)
+	}
+
+	if result.ClearDiff != "" {
+		fmt.Printf("\nclear program diff (-local +on-chain):\n%s", result.ClearDiff)
+	}
+
+	if result.Detected != "" && result.Detected != result.Version {
+		return errors.Errorf("app %d runs the %s contract, not the %s one", result.AppID, result.Detected, result.Version)
+	}
+
+	if !result.OK() {
+		return errors.Errorf("app %d doesn't match the %s contract", result.AppID, result.Version)
+	}
+
+	return nil
+}
+
+func mark(ok bool) string {
+	if ok {
+		return "ok"
+	}
+
+	return "MISMATCH"
+}
@@ -0,0 +1,192 @@
+import base64
+import json
+
+"""ARC-4 descriptions of the King Of Algo contracts and their ARC-56 app specs, the Go client is generated from the app specs"""
+
+def method(name, args, returns, desc, defaults=None):
+    """defaults maps the name of an arg to its literal default, a uint64"""
+    defaults = defaults or {}
+    described_args = []
+    for (type, arg_name, arg_desc) in args:
+        arg = {"type": type, "name": arg_name, "desc": arg_desc}
+        if arg_name in defaults:
+            arg["defaultValue"] = {
+                "source": "literal",
+                "data": base64.b64encode(defaults[arg_name].to_bytes(8, "big")).decode(),
+                "type": "uint64",
+            }
+        described_args.append(arg)
+
+    return {
+        "name": name,
+        "desc": desc,
+        "args": described_args,
+        "returns": {"type": returns},
+    }
+
+# Defaults of the create args, the client flags start from them.
+default_reign_period = 24 * 60 * 60
+default_upgrade_delay = 48 * 60 * 60
+
+def key(name, value_type, desc):
+    return (name, value_type, desc)
+
+def shared_keys():
+    """The global state keys of both variants"""
+    return [
+        key("king", "address", "The king, empty when there is none"),
+        key("admin", "address", "Receives the admin fees, holds the update and delete rights"),
+        key("king_price", "uint64", "Price of the next claim during the reign"),
+        key("init_price", "uint64", "Price of the first claim of a reign"),
+        key("end_of_reign_timestamp", "uint64", "Unix time the reign ends"),
+        key("reign_period", "uint64", "Seconds"),
+        key("admin_fee", "uint64", "Percentage of the price paid to the admin"),
+        key("reward_multiplier", "uint64", "Percentage of the price paid to the previous king"),
+        key("pending_admin", "address", "The proposed admin, empty when there is none"),
+        key("paused", "uint64", "1 when the admin paused the claims"),
+        key("next_admin_fee", "uint64", "Admin fee of the next reign, set with the other params of the next reign"),
+        key("next_reward_multiplier", "uint64", "Reward multiplier of the next reign"),
+        key("next_reign_period", "uint64", "Reign period of the next reign"),
+        key("upgrade_delay", "uint64", "Seconds between the announce of an update or delete and its execution"),
+        key("upgrade_hash", "AVMBytes", "sha256 of the announced programs, \"delete\" for an announced delete"),
+        key("upgrade_at", "uint64", "Unix time the announced update or delete can run"),
+    ]
+
+def admin_methods():
+    """The methods shared by the variants"""
+    return [
+        method("propose_admin", [("address", "admin", "The next admin")], "void",
+            "Proposes the next admin, who becomes admin once it accepts. Proposing again replaces the pending admin."),
+        method("accept_admin", [], "void", "The pending admin takes over the admin fees and the update and delete rights."),
+        method("cancel_admin", [], "void", "Removes the pending admin."),
+        method("pause", [], "void", "Stops the claims, the king can still abdicate and the admin delete the app."),
+        method("unpause", [], "void", "Lets the players claim again."),
+        method("set_params", [
+                ("uint64", "admin_fee", "Percentage of the price paid to the admin, up to 10"),
+                ("uint64", "reward_multiplier", "Percentage of the price paid to the previous king, from 50 to 90"),
+                ("uint64", "reign_period", "Seconds, from 1 hour to 30 days"),
+            ], "void",
+            "Sets the params of the next reign, they apply when the current reign is over, right away when there is no king."),
+        method("announce_upgrade", [("byte[32]", "hash", "sha256 of the approval and clear programs")], "void",
+            "Announces the programs of an update, allowed once the upgrade delay passed. Announcing again restarts the delay."),
+        method("announce_delete", [], "void", "Announces the delete of the app, allowed once the upgrade delay passed."),
+        method("cancel_upgrade", [], "void", "Removes the announced update or delete."),
+    ]
+
+def king_of_algo_contract():
+    return {
+        "name": "KingOfAlgo",
+        "desc": "Claim the throne by paying the king price, split between the admin, the app account and the previous king.",
+        "methods": [
+            method("create", [
+                    ("uint64", "reign_period", "Seconds"),
+                    ("uint64", "upgrade_delay", "Seconds between the announce of an update or delete and its execution"),
+                ], "void", "Creates the app, a NoOp call with the app ID 0.",
+                {"reign_period": default_reign_period, "upgrade_delay": default_upgrade_delay}),
+            method("claim_throne", [
+                    ("pay", "admin_fee", "Admin fee to the admin"),
+                    ("pay", "compensation", "Compensation to the app account, plus 0.1 ALGO for the min balance of the crown"),
+                    ("pay", "reward", "Reward to the king"),
+                    ("string", "message", "The message of the new king JSON encoded, written in the crown"),
+                ], "void",
+                "Overthrows the king, or claims the throne at the init price once the reign ended, and mints the crown of the new king. The fee covers the inner txs."),
+            method("claim_empty_throne", [
+                    ("pay", "admin_fee", "Admin fee to the admin"),
+                    ("pay", "compensation", "Compensation to the app account, plus 0.1 ALGO for the min balance of the crown"),
+                    ("string", "message", "The message of the new king JSON encoded, written in the crown"),
+                ], "void", "Claims the throne when there is no king and mints the crown of the new king."),
+            method("collect_crown", [("uint64", "crown", "Asset ID of the crown, in the foreign assets")], "void",
+                "The king the crown was minted for collects it once opted in, paying the fee of the inner tx."),
+            method("burn_crown", [("uint64", "crown", "Asset ID of the crown, in the foreign assets")], "void",
+                "Once the announced delete can run, the admin destroys a crown the app account holds and gets its min balance."),
+            method("abdicate", [], "uint64",
+                "The king ends the reign and collects the compensation, paying the fee of the inner tx. Returns the compensation."),
+        ] + admin_methods(),
+    }
+
+def king_of_algo_asa_contract():
+    return {
+        "name": "KingOfAlgoASA",
+        "desc": "King Of Algo priced in an ASA: the admin fee, compensation and reward are transfers of the asset.",
+        "methods": [
+            method("create", [
+                    ("uint64", "reign_period", "Seconds"),
+                    ("uint64", "asset", "ID of the asset the throne is priced in"),
+                    ("uint64", "init_price", "Base units of the asset"),
+                    ("uint64", "upgrade_delay", "Seconds between the announce of an update or delete and its execution"),
+                ], "void", "Creates the app, a NoOp call with the app ID 0.",
+                {"reign_period": default_reign_period, "upgrade_delay": default_upgrade_delay}),
+            method("opt_in_asset", [], "void", "Opts the app account in to the asset, before the first claim."),
+            method("claim_throne", [
+                    ("axfer", "admin_fee", "Admin fee to the admin"),
+                    ("axfer", "compensation", "Compensation to the app account"),
+                    ("axfer", "reward", "Reward to the king, or to the app account when the king opted out of the asset"),
+                ], "void",
+                "Overthrows the king, or claims the throne at the init price once the reign ended. The fee covers the inner tx. "
+                "The king is in the foreign accounts, the compensation of a king who opted out stays in the app account."),
+            method("claim_empty_throne", [
+                    ("axfer", "admin_fee", "Admin fee to the admin"),
+                    ("axfer", "compensation", "Compensation to the app account"),
+                ], "void", "Claims the throne when there is no king."),
+            method("abdicate", [], "uint64",
+                "The king ends the reign and collects the compensation, paying the fee of the inner tx. Returns the compensation."),
+        ] + admin_methods(),
+    }
+
+def king_of_algo_keys():
+    return shared_keys() + [
+        key("reign", "uint64", "Number of claims so far"),
+        key("crown", "uint64", "Crown of the last king, 0 before the first claim"),
+        key("crowns", "uint64", "Number of crowns the app created and didn't burn, their min balance stays in the app account"),
+    ]
+
+def king_of_algo_asa_keys():
+    return shared_keys() + [
+        key("asset", "uint64", "ID of the asset the throne is priced in"),
+        key("reign", "uint64", "Number of claims so far"),
+    ]
+
+def app_spec(contract, keys):
+    """The ARC-56 app spec of the contract: the methods with the actions they allow and the global state"""
+    methods = []
+    for m in contract["methods"]:
+        m = dict(m)
+        if m["name"] == "create":
+            m["actions"] = {"create": ["NoOp"], "call": []}
+        else:
+            m["actions"] = {"create": [], "call": ["NoOp"]}
+        methods.append(m)
+
+    ints = len([k for k in keys if k[1] == "uint64"])
+    return {
+        "arcs": [4, 56],
+        "name": contract["name"],
+        "desc": contract["desc"],
+        "structs": {},
+        "methods": methods,
+        "state": {
+            "schema": {
+                "global": {"ints": ints, "bytes": len(keys) - ints},
+                "local": {"ints": 0, "bytes": 0},
+            },
+            "keys": {
+                "global": {
+                    name: {
+                        "keyType": "AVMString",
+                        "valueType": value_type,
+                        "key": base64.b64encode(name.encode()).decode(),
+                        "desc": desc,
+                    } for (name, value_type, desc) in keys
+                },
+                "local": {},
+                "box": {},
+            },
+            "maps": {"global": {}, "local": {}, "box": {}},
+        },
+        # The timelocked update and delete are bare calls of the admin.
+        "bareActions": {"create": [], "call": ["UpdateApplication", "DeleteApplication"]},
+    }
+
+def write_contract(path, contract):
+    with open(path, "w") as f:
+        f.write(json.dumps(contract, indent=4))
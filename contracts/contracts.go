@@ -0,0 +1,94 @@
+// Package contracts embeds the TEAL programs compiled from king_of_algo.py, king_of_algo_asa.py and factory.py,
+// and the ARC-4 descriptions of their methods. The programs of the previous versions are kept in their directory
+// so the apps deployed with them can still be verified.
+package contracts
+
+import (
+	_ "embed"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the version of the contract in this tree, it matches the version in the tx notes.
+const Version = "v2"
+
+// Versions are the contract versions whose programs are embedded, from the oldest. v1 is the throne priced in ALGO
+// without crowns, admin rotation, pause, params or timelock; it has no ASA variant, factory or ARC-4 description.
+var Versions = []string{"v1", Version}
+
+var (
+	//go:embed v1/approval.teal
+	v1ApprovalProgram []byte
+
+	//go:embed v1/clear.teal
+	v1ClearProgram []byte
+
+	//go:embed approval.teal
+	approvalProgram []byte
+
+	//go:embed clear.teal
+	clearProgram []byte
+
+	//go:embed approval_asa.teal
+	asaApprovalProgram []byte
+
+	//go:embed factory_approval.teal
+	factoryApprovalProgram []byte
+
+	//go:embed factory_clear.teal
+	factoryClearProgram []byte
+
+	//go:embed contract.json
+	contract []byte
+
+	//go:embed contract_asa.json
+	asaContract []byte
+)
+
+// Programs returns the approval and clear TEAL sources of the given contract version.
+func Programs(version string) ([]byte, []byte, error) {
+	switch version {
+	case Version:
+		return approvalProgram, clearProgram, nil
+	case "v1":
+		return v1ApprovalProgram, v1ClearProgram, nil
+	default:
+		return nil, nil, errors.Errorf("unknown contract version %q", version)
+	}
+}
+
+// ASAPrograms returns the approval and clear TEAL sources of the variant priced in an ASA of the given contract version.
+func ASAPrograms(version string) ([]byte, []byte, error) {
+	if version != Version {
+		return nil, nil, errors.Errorf("unknown contract version %q", version)
+	}
+
+	return asaApprovalProgram, clearProgram, nil
+}
+
+// FactoryPrograms returns the approval and clear TEAL sources of the factory creating the apps of the given contract version.
+func FactoryPrograms(version string) ([]byte, []byte, error) {
+	if version != Version {
+		return nil, nil, errors.Errorf("unknown contract version %q", version)
+	}
+
+	return factoryApprovalProgram, factoryClearProgram, nil
+}
+
+// Contract returns the ARC-4 contract JSON of the given contract version.
+func Contract(version string) ([]byte, error) {
+	if version != Version {
+		return nil, errors.Errorf("unknown contract version %q", version)
+	}
+
+	return contract, nil
+}
+
+// ASAContract returns the ARC-4 contract JSON of the variant priced in an ASA of the given contract version.
+func ASAContract(version string) ([]byte, error) {
+	if version != Version {
+		return nil, errors.Errorf("unknown contract version %q", version)
+	}
+
+	return asaContract, nil
+}
@@ -0,0 +1,275 @@
+import os
+from pyteal import *
+
+"""King Of Algo throne factory"""
+
+admin_address_key = Bytes("admin")
+child_hash_key = Bytes("child_hash")
+count_key = Bytes("count")
+upgrade_delay_key = Bytes("upgrade_delay")
+upgrade_hash_key = Bytes("upgrade_hash")
+upgrade_at_key = Bytes("upgrade_at")
+# The children are the other keys, the app ID as 8 bytes with the creation timestamp as value.
+
+max_children = Int(58) # The global state can't hold more than 64 keys.
+child_min_balance = Int(100000)
+program_page_size = Int(2048)
+
+def approval_program():
+    program = Cond(
+        [Txn.application_id() == Int(0), handle_creation()],
+        [Txn.on_completion() == OnComplete.UpdateApplication, handle_update()],
+        [Txn.on_completion() == OnComplete.DeleteApplication, handle_delete()],
+        [Txn.on_completion() == OnComplete.NoOp, handle_noop()]
+    )
+    return compileTeal(program, Mode.Application, version=6)
+
+def handle_creation() -> Expr:
+    """Args: the sha256 of the approval and clear programs the children run, and the upgrade delay of the factory"""
+    return Seq(
+        App.globalPut(admin_address_key, Txn.sender()),
+        App.globalPut(child_hash_key, Txn.application_args[0]),
+        App.globalPut(count_key, Int(0)),
+        App.globalPut(upgrade_delay_key, Btoi(Txn.application_args[1])),
+        Approve()
+    )
+
+def handle_update() -> Expr:
+    """The factory is the admin of its children, it has the timelock of the thrones:
+    the admin updates to the programs announced with announce_upgrade once the delay passed"""
+    upgrade_hash = App.globalGetEx(Int(0), upgrade_hash_key)
+
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        upgrade_hash,
+        Assert(upgrade_hash.hasValue()),
+        Assert(Global.latest_timestamp() >= App.globalGet(upgrade_at_key)),
+        Assert(Sha256(Concat(Txn.approval_program(), Txn.clear_state_program())) == upgrade_hash.value()),
+        App.globalDel(upgrade_hash_key),
+        App.globalDel(upgrade_at_key),
+        Approve()
+    )
+
+def handle_delete() -> Expr:
+    """The factory can only be deleted once all its children are retired"""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        Assert(App.globalGet(count_key) == Int(0)),
+        close_app_account_to(App.globalGet(admin_address_key)),
+        Approve()
+    )
+
+def handle_noop() -> Expr:
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        Cond(
+            [Txn.application_args[0] == Bytes("create"), handle_create_child()],
+            [Txn.application_args[0] == Bytes("retire"), handle_retire_child()],
+            [Txn.application_args[0] == Bytes("withdraw"), Seq(send_surplus_to_admin(), Approve())],
+            [Txn.application_args[0] == Bytes("program"), Approve()], # The tail of the approval program of a create.
+            [Txn.application_args[0] == Bytes("announce_retire"), handle_announce_retire()],
+            [Txn.application_args[0] == Bytes("announce_upgrade"), handle_announce_upgrade()],
+            [Txn.application_args[0] == Bytes("cancel_upgrade"), handle_cancel_upgrade()],
+            # The admin methods of the children, the factory being their admin.
+            [Txn.application_args[0] == Bytes("burn_crown"), handle_burn_crown()],
+            [Txn.application_args[0] == Bytes("pause"), Seq(call_child("pause()void"), Approve())],
+            [Txn.application_args[0] == Bytes("unpause"), Seq(call_child("unpause()void"), Approve())],
+            [
+                Txn.application_args[0] == Bytes("set_params"),
+                Seq(
+                    call_child("set_params(uint64,uint64,uint64)void", [Txn.application_args[1], Txn.application_args[2], Txn.application_args[3]]),
+                    Approve()
+                )
+            ],
+            [Txn.application_args[0] == Bytes("hand_over"), handle_hand_over()],
+        )
+    )
+
+def handle_announce_upgrade() -> Expr:
+    """Arg: the sha256 of the approval and clear programs of the upgrade. Announcing again restarts the delay."""
+    return Seq(
+        Assert(Len(Txn.application_args[1]) == Int(32)),
+        App.globalPut(upgrade_hash_key, Txn.application_args[1]),
+        App.globalPut(upgrade_at_key, Global.latest_timestamp() + App.globalGet(upgrade_delay_key)),
+        Approve()
+    )
+
+def handle_cancel_upgrade() -> Expr:
+    return Seq(
+        App.globalDel(upgrade_hash_key),
+        App.globalDel(upgrade_at_key),
+        Approve()
+    )
+
+def call_child(signature: str, args: list = [], assets: list = []) -> Expr:
+    """Calls the method of the child, the first foreign app, with the args and foreign assets"""
+    child = Txn.applications[1]
+    fields = {
+        TxnField.type_enum: TxnType.ApplicationCall,
+        TxnField.application_id: child,
+        TxnField.on_completion: OnComplete.NoOp,
+        TxnField.application_args: [MethodSignature(signature)] + args,
+    }
+    if assets:
+        fields[TxnField.assets] = assets
+    fields[TxnField.fee] = Int(0)
+
+    return Seq(
+        Assert(App.globalGet(Itob(child)) > Int(0)),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(fields),
+        InnerTxnBuilder.Submit(),
+    )
+
+def handle_burn_crown() -> Expr:
+    """Burns a crown the child still holds once its retire is announced, the child pays the min balance of the crown
+    to the factory, which forwards it to the admin. Arg: the crown, the first foreign asset."""
+    return Seq(
+        call_child("burn_crown(uint64)void", [Txn.application_args[1]], [Txn.assets[0]]),
+        send_surplus_to_admin(),
+        Approve()
+    )
+
+def handle_hand_over() -> Expr:
+    """Proposes the new admin, the second arg, to the child and forgets the child: the new admin accepts it
+    with accept_admin and runs it without the factory"""
+    child = Txn.applications[1]
+
+    return Seq(
+        call_child("propose_admin(address)void", [Txn.application_args[1]]),
+        App.globalDel(Itob(child)),
+        App.globalPut(count_key, App.globalGet(count_key) - Int(1)),
+        Approve()
+    )
+
+def handle_create_child() -> Expr:
+    """Creates a child running the approved programs and funds its account.
+    Args: create, approval program head, clear program, reign period, global uints, global byte slices, upgrade delay.
+    The approval program doesn't fit in the args of one tx, its tail is the second arg of the program call after this one.
+    The payment before the call covers the child account and the min balance the factory holds for the child."""
+    fundingTx = Gtxn[0]
+    programTx = Gtxn[2]
+    child = ScratchVar(TealType.uint64)
+    approval = ScratchVar(TealType.bytes)
+
+    return Seq(
+        Assert(Global.group_size() == Int(3)),
+        Assert(Txn.group_index() == Int(1)),
+        Assert(fundingTx.type_enum() == TxnType.Payment),
+        Assert(fundingTx.receiver() == Global.current_application_address()),
+        Assert(fundingTx.close_remainder_to() == Global.zero_address()),
+        Assert(fundingTx.rekey_to() == Global.zero_address()),
+        Assert(programTx.type_enum() == TxnType.ApplicationCall),
+        Assert(programTx.application_id() == Global.current_application_id()),
+        Assert(programTx.rekey_to() == Global.zero_address()),
+        approval.store(Concat(Txn.application_args[1], programTx.application_args[1])),
+        Assert(App.globalGet(count_key) < max_children),
+        Assert(Sha256(Concat(approval.load(), Txn.application_args[2])) == App.globalGet(child_hash_key)),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.ApplicationCall,
+                TxnField.approval_program: approval.load(),
+                TxnField.clear_state_program: Txn.application_args[2],
+                TxnField.application_args: [MethodSignature("create(uint64,uint64)void"), Txn.application_args[3], Txn.application_args[6]],
+                TxnField.global_num_uints: Btoi(Txn.application_args[4]),
+                TxnField.global_num_byte_slices: Btoi(Txn.application_args[5]),
+                TxnField.extra_program_pages: (Len(approval.load()) + Len(Txn.application_args[2]) - Int(1)) / program_page_size,
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        child.store(InnerTxn.created_application_id()),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.Payment,
+                TxnField.receiver: Sha512_256(Concat(Bytes("appID"), Itob(child.load()))),
+                TxnField.amount: child_min_balance,
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        App.globalPut(Itob(child.load()), Global.latest_timestamp()),
+        App.globalPut(count_key, App.globalGet(count_key) + Int(1)),
+        Log(Itob(child.load())),
+        Approve()
+    )
+
+def handle_announce_retire() -> Expr:
+    """Announces the delete of the child, the first foreign app, it can be retired once its upgrade delay passed"""
+    return Seq(
+        call_child("announce_delete()void"),
+        Approve()
+    )
+
+def handle_retire_child() -> Expr:
+    """Deletes the child, which settles its king and closes its account to the factory, and forwards the surplus to the admin.
+    The child is the first foreign app and its king, if any, the first foreign account."""
+    child = Txn.applications[1]
+
+    return Seq(
+        Assert(App.globalGet(Itob(child)) > Int(0)),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.ApplicationCall,
+                TxnField.application_id: child,
+                TxnField.on_completion: OnComplete.DeleteApplication,
+                TxnField.fee: Int(0),
+            }
+        ),
+        If(Txn.accounts.length() > Int(0)).Then(
+            InnerTxnBuilder.SetField(TxnField.accounts, [Txn.accounts[1]]),
+        ),
+        InnerTxnBuilder.Submit(),
+        App.globalDel(Itob(child)),
+        App.globalPut(count_key, App.globalGet(count_key) - Int(1)),
+        send_surplus_to_admin(),
+        Approve()
+    )
+
+@Subroutine(TealType.none)
+def send_surplus_to_admin() -> Expr:
+    """The admin fees of the children and the retired children accounts end up in the factory account"""
+    return Seq(
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.Payment,
+                TxnField.receiver: App.globalGet(admin_address_key),
+                TxnField.amount: Minus(Balance(Global.current_application_address()), MinBalance(Global.current_application_address())),
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+    )
+
+def close_app_account_to(receiver: Expr) -> Expr:
+    """The admin pays for the fee of the inner tx when deleting the app"""
+    return Seq(
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.Payment,
+                TxnField.receiver: receiver,
+                TxnField.amount: Int(0),
+                TxnField.close_remainder_to: receiver,
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+    )
+
+def clear_state_program():
+   program = Approve()
+   return compileTeal(program, Mode.Application, version=6)
+
+if __name__ == "__main__":
+    path = os.path.dirname(os.path.abspath(__file__))
+
+    with open(os.path.join(path,"factory_approval.teal"), "w") as f:
+        f.write(approval_program())
+
+    with open(os.path.join(path, "factory_clear.teal"), "w") as f:
+        f.write(clear_state_program())
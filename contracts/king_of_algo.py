@@ -1,7 +1,8 @@
 import os 
 from pyteal import *
+from arc4 import app_spec, king_of_algo_contract, king_of_algo_keys, write_contract
 
-"""King Of Algo"""
+"""King Of Algo, the NoOp calls are ARC-4 methods described in contract.json"""
 
 king_address_key = Bytes("king")
 king_price_key = Bytes("king_price")
@@ -11,6 +12,17 @@ admin_address_key = Bytes("admin")
 reign_period_key = Bytes("reign_period")
 admin_fee_key = Bytes("admin_fee")
 reward_multiplier_key = Bytes("reward_multiplier")
+reign_key = Bytes("reign")
+crown_key = Bytes("crown")
+crowns_key = Bytes("crowns")
+pending_admin_key = Bytes("pending_admin")
+paused_key = Bytes("paused")
+next_admin_fee_key = Bytes("next_admin_fee")
+next_reward_multiplier_key = Bytes("next_reward_multiplier")
+next_reign_period_key = Bytes("next_reign_period")
+upgrade_delay_key = Bytes("upgrade_delay")
+upgrade_hash_key = Bytes("upgrade_hash")
+upgrade_at_key = Bytes("upgrade_at")
 
 empty_str = Bytes("")
 initial_king_price = Int(100000) # Has to be > 0
@@ -18,6 +30,17 @@ admin_fee_fp = Int(5) # 0.05 with 1/100 scaling.
 reward_multiplier_fp = Int(75) # 0.75 with 1/100 scaling
 fixed_point_scaling = Int(100)
 king_price_multiplier = Int(2)
+crown_min_balance = Int(100000) # Every crown the app account created raises its min balance, the claim funds it.
+max_message_length = Int(256) # The ARC-69 note can't be longer than 1024 bytes.
+# Bounds of the params the admin can change on a live app.
+max_admin_fee = Int(10)
+min_reward_multiplier = Int(50)
+max_reward_multiplier = Int(90)
+max_admin_fee_and_reward = Int(95) # What is left is the compensation of the king.
+min_reign_period = Int(3600)
+max_reign_period = Int(2592000) # 30 days.
+delete_announcement = Bytes("delete") # Stored instead of the hash of the programs, it can't be a sha256.
+abi_return_prefix = Bytes("base16", "151f7c75") # The log of the return value of an ARC-4 method starts with it.
 
 def approval_program():
     handle_optin = Reject()
@@ -27,39 +50,288 @@ def approval_program():
         [Txn.application_id() == Int(0), handle_creation()],
         [Txn.on_completion() == OnComplete.OptIn, handle_optin],
         [Txn.on_completion() == OnComplete.CloseOut, handle_closeout],
-        [Txn.on_completion() == OnComplete.UpdateApplication, allow_when_admin()],
-        [Txn.on_completion() == OnComplete.DeleteApplication, allow_when_admin()],
-        [Txn.on_completion() == OnComplete.NoOp, handle_claim()]
+        [Txn.on_completion() == OnComplete.UpdateApplication, handle_update()],
+        [Txn.on_completion() == OnComplete.DeleteApplication, handle_delete()],
+        [Txn.on_completion() == OnComplete.NoOp, handle_noop()]
     )
     return compileTeal(program, Mode.Application, version=6)
 
-# allow update if the sender is the admin
-def allow_when_admin() -> Expr:
+def handle_update() -> Expr:
+    """The admin updates to the programs announced with announce_upgrade once the delay passed,
+    so the players see what they will play against before it runs"""
+    upgrade_hash = App.globalGetEx(Int(0), upgrade_hash_key)
+
     return Seq(
         Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        upgrade_hash,
+        Assert(upgrade_hash.hasValue()),
+        Assert(Global.latest_timestamp() >= App.globalGet(upgrade_at_key)),
+        Assert(Sha256(Concat(Txn.approval_program(), Txn.clear_state_program())) == upgrade_hash.value()),
+        App.globalDel(upgrade_hash_key),
+        App.globalDel(upgrade_at_key),
+        Approve()
+    )
+
+def assert_delete_announced() -> Expr:
+    upgrade_hash = App.globalGetEx(Int(0), upgrade_hash_key)
+
+    return Seq(
+        upgrade_hash,
+        Assert(upgrade_hash.hasValue()),
+        Assert(upgrade_hash.value() == delete_announcement),
+        Assert(Global.latest_timestamp() >= App.globalGet(upgrade_at_key)),
+    )
+
+def handle_announce_upgrade(announcement: Expr) -> Expr:
+    """The upgrade, or the delete, can run once the delay set at creation passed. Announcing again restarts the delay."""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        App.globalPut(upgrade_hash_key, announcement),
+        App.globalPut(upgrade_at_key, Global.latest_timestamp() + App.globalGet(upgrade_delay_key)),
+        Approve()
+    )
+
+def handle_cancel_upgrade() -> Expr:
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        App.globalDel(upgrade_hash_key),
+        App.globalDel(upgrade_at_key),
+        Approve()
+    )
+
+def handle_delete() -> Expr:
+    """Settles the current king's compensation and returns what is left in the app account to the admin.
+    An account can't be closed while the assets it created exist: the admin burns the crowns the app account
+    still holds first, the min balance of the crowns the kings collected stays in the app account."""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        assert_delete_announced(),
+        If(App.globalGet(king_address_key) != empty_str).Then(
+            send_compensation_to_the_dead_king(App.globalGet(king_address_key), get_last_king_compensation()),
+        ),
+        If(App.globalGet(crowns_key) == Int(0))
+        .Then(close_app_account_to(App.globalGet(admin_address_key)))
+        .Else(send_payment(App.globalGet(admin_address_key), get_last_king_compensation())),
         Approve()
     )
 
 def handle_creation() -> Expr:
+    """create(uint64,uint64)void: the reign period and the upgrade delay"""
     return Seq(
+        Assert(Txn.application_args[0] == MethodSignature("create(uint64,uint64)void")),
         set_admin(),
         set_admin_fee(),
         set_period(),
         set_reward_multiplier(),
+        App.globalPut(upgrade_delay_key, Btoi(Txn.application_args[2])),
+        App.globalPut(reign_key, Int(0)),
+        App.globalPut(crown_key, Int(0)),
+        App.globalPut(crowns_key, Int(0)),
         set_init_state(),
         Approve()
     )
 
-def handle_claim() -> Expr:
+def handle_noop() -> Expr:
     return Cond(
-        [App.globalGet(king_address_key) == empty_str, handle_when_is_the_first_king()],
-        [App.globalGet(king_address_key) != empty_str, handle_when_king_is_set()]
+        [Txn.application_args[0] == MethodSignature("collect_crown(uint64)void"), handle_collect_crown()],
+        [Txn.application_args[0] == MethodSignature("burn_crown(uint64)void"), handle_burn_crown()],
+        # The group of a claim depends on the king, handle_claim checks it matches.
+        [
+            Or(
+                Txn.application_args[0] == MethodSignature("claim_throne(pay,pay,pay,string)void"),
+                Txn.application_args[0] == MethodSignature("claim_empty_throne(pay,pay,string)void"),
+            ),
+            handle_claim()
+        ],
+        [Txn.application_args[0] == MethodSignature("abdicate()uint64"), handle_abdicate()],
+        *admin_methods(),
+    )
+
+def admin_methods():
+    """The methods shared with the variant priced in an ASA, as the branches of a Cond"""
+    return [
+        [Txn.application_args[0] == MethodSignature("propose_admin(address)void"), handle_propose_admin()],
+        [Txn.application_args[0] == MethodSignature("accept_admin()void"), handle_accept_admin()],
+        [Txn.application_args[0] == MethodSignature("cancel_admin()void"), handle_cancel_admin()],
+        [Txn.application_args[0] == MethodSignature("pause()void"), handle_set_paused(Int(1))],
+        [Txn.application_args[0] == MethodSignature("unpause()void"), handle_set_paused(Int(0))],
+        [Txn.application_args[0] == MethodSignature("set_params(uint64,uint64,uint64)void"), handle_set_params()],
+        [Txn.application_args[0] == MethodSignature("announce_upgrade(byte[32])void"), handle_announce_upgrade(announced_hash())],
+        [Txn.application_args[0] == MethodSignature("announce_delete()void"), handle_announce_upgrade(delete_announcement)],
+        [Txn.application_args[0] == MethodSignature("cancel_upgrade()void"), handle_cancel_upgrade()],
+    ]
+
+def announced_hash() -> Expr:
+    """The sha256 of the approval and clear programs, the second argument"""
+    return Seq(
+        Assert(Len(Txn.application_args[1]) == Int(32)),
+        Txn.application_args[1],
+    )
+
+def handle_collect_crown() -> Expr:
+    """The king a crown was minted for collects it once opted in, the reserve of the crown is the king.
+    Arg: the crown, in the foreign assets. The king pays for the fee of the inner tx."""
+    crown = Btoi(Txn.application_args[1])
+    creator = AssetParam.creator(crown)
+    reserve = AssetParam.reserve(crown)
+
+    return Seq(
+        creator,
+        Assert(creator.hasValue()),
+        Assert(creator.value() == Global.current_application_address()),
+        reserve,
+        Assert(reserve.value() == Txn.sender()),
+        Assert(Txn.fee() >= Global.min_txn_fee() * Int(2)),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.AssetTransfer,
+                TxnField.xfer_asset: crown,
+                TxnField.asset_receiver: Txn.sender(),
+                TxnField.asset_amount: Int(1),
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        Approve()
+    )
+
+def handle_burn_crown() -> Expr:
+    """Once the announced delete can run, the admin destroys a crown the app account still holds, never collected or
+    given back, and gets its min balance. Arg: the crown, in the foreign assets. The admin pays for the fees of the inner txs."""
+    crown = Btoi(Txn.application_args[1])
+    creator = AssetParam.creator(crown)
+
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        assert_delete_announced(),
+        creator,
+        Assert(creator.hasValue()),
+        Assert(creator.value() == Global.current_application_address()),
+        # Only the holder of the whole supply can destroy the crown.
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.AssetConfig,
+                TxnField.config_asset: crown,
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        App.globalPut(crowns_key, App.globalGet(crowns_key) - Int(1)),
+        If(App.globalGet(crown_key) == crown).Then(App.globalPut(crown_key, Int(0))),
+        send_payment(Txn.sender(), crown_min_balance),
+        Approve()
+    )
+
+def handle_abdicate() -> Expr:
+    """The king ends the reign, collects the compensation at once and the throne goes back to the init price.
+    The king pays for the fee of the inner tx. Returns the compensation."""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(king_address_key)),
+        Assert(Txn.fee() >= Global.min_txn_fee() * Int(2)),
+        send_compensation_to_the_dead_king(Txn.sender(), get_last_king_compensation()),
+        abi_return_uint64(InnerTxn.amount()),
+        set_init_state(),
+        Approve()
+    )
+
+def handle_propose_admin() -> Expr:
+    """The admin proposes the next admin, the address in the second argument. Proposing again replaces the pending admin."""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        Assert(Len(Txn.application_args[1]) == Int(32)),
+        App.globalPut(pending_admin_key, Txn.application_args[1]),
+        Approve()
+    )
+
+def handle_accept_admin() -> Expr:
+    """The pending admin takes over the admin fees of the next claims and the update and delete rights"""
+    pending_admin = App.globalGetEx(Int(0), pending_admin_key)
+
+    return Seq(
+        pending_admin,
+        Assert(pending_admin.hasValue()),
+        Assert(Txn.sender() == pending_admin.value()),
+        App.globalPut(admin_address_key, Txn.sender()),
+        App.globalDel(pending_admin_key),
+        Approve()
+    )
+
+def handle_cancel_admin() -> Expr:
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        App.globalDel(pending_admin_key),
+        Approve()
+    )
+
+def handle_set_paused(paused: Int) -> Expr:
+    """While paused nobody can claim the throne, the king can still abdicate and the admin delete the app,
+    which settle the compensation. The key is missing until the first pause, which reads as 0."""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        App.globalPut(paused_key, paused),
+        Approve()
+    )
+
+def handle_set_params() -> Expr:
+    """Args: admin fee, reward multiplier and reign period of the next reign, within the bounds.
+    They apply when the current reign is over, right away when there is no king. Setting them again replaces them."""
+    admin_fee = Btoi(Txn.application_args[1])
+    reward_multiplier = Btoi(Txn.application_args[2])
+    reign_period = Btoi(Txn.application_args[3])
+
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        Assert(admin_fee <= max_admin_fee),
+        Assert(reward_multiplier >= min_reward_multiplier),
+        Assert(reward_multiplier <= max_reward_multiplier),
+        Assert(admin_fee + reward_multiplier <= max_admin_fee_and_reward),
+        Assert(reign_period >= min_reign_period),
+        Assert(reign_period <= max_reign_period),
+        App.globalPut(next_admin_fee_key, admin_fee),
+        App.globalPut(next_reward_multiplier_key, reward_multiplier),
+        App.globalPut(next_reign_period_key, reign_period),
+        If(App.globalGet(king_address_key) == empty_str).Then(apply_pending_params()),
+        Approve()
+    )
+
+@Subroutine(TealType.none)
+def apply_pending_params() -> Expr:
+    """The next params are set together, the admin fee tells if there are some"""
+    next_admin_fee = App.globalGetEx(Int(0), next_admin_fee_key)
+
+    return Seq(
+        next_admin_fee,
+        If(next_admin_fee.hasValue()).Then(
+            Seq(
+                App.globalPut(admin_fee_key, next_admin_fee.value()),
+                App.globalPut(reward_multiplier_key, App.globalGet(next_reward_multiplier_key)),
+                App.globalPut(reign_period_key, App.globalGet(next_reign_period_key)),
+                App.globalDel(next_admin_fee_key),
+                App.globalDel(next_reward_multiplier_key),
+                App.globalDel(next_reign_period_key),
+            )
+        ),
+    )
+
+def assert_not_paused() -> Expr:
+    return Assert(App.globalGet(paused_key) == Int(0))
+
+def handle_claim() -> Expr:
+    return Seq(
+        assert_not_paused(),
+        Cond(
+            [App.globalGet(king_address_key) == empty_str, handle_when_is_the_first_king()],
+            [App.globalGet(king_address_key) != empty_str, handle_when_king_is_set()]
+        )
     )
 
 def handle_when_is_the_first_king() -> Expr:
-    appTx = Gtxn[0]
-    adminFeeTx = Gtxn[1]
-    compensationTx = Gtxn[2]
+    """claim_empty_throne: the payments are the args of the method, before the app call"""
+    adminFeeTx = Gtxn[0]
+    compensationTx = Gtxn[1]
+    appTx = Gtxn[2]
 
     return Cond(
         [
@@ -79,10 +351,11 @@ def handle_when_is_the_first_king() -> Expr:
     )
 
 def handle_when_king_is_set() -> Expr:
-    appTx = Gtxn[0]
-    adminFeeTx = Gtxn[1]
-    compensationTx = Gtxn[2]
-    rewardTx = Gtxn[3]
+    """claim_throne: the payments are the args of the method, before the app call"""
+    adminFeeTx = Gtxn[0]
+    compensationTx = Gtxn[1]
+    rewardTx = Gtxn[2]
+    appTx = Gtxn[3]
 
     return Cond(
         [
@@ -100,8 +373,10 @@ def handle_when_king_is_set() -> Expr:
                 )
                 .Else(
                     assert_fee_for_inner_tx(),
+                    apply_pending_params(), # The claim starts the next reign, it pays with its params.
                     assert_end_of_reign_amounts(adminFeeTx, compensationTx, rewardTx),
-                    send_compensation_to_the_dead_king(rewardTx.receiver(), get_last_king_compensation()),
+                    # The claim already paid for the min balance of the crown it mints.
+                    send_compensation_to_the_dead_king(rewardTx.receiver(), get_last_king_compensation() - crown_min_balance),
                     set_init_state(),
                 ),
                 set_new_king(),
@@ -144,29 +419,30 @@ def validate_admin_fee_tx(tx: TxnObject) -> Expr:
 def validate_tx_group(num_of_transactions) -> Expr:
     return Seq(
         Assert(Global.group_size() == Int(num_of_transactions)),
-        Assert(Txn.group_index() == Int(0)),
+        Assert(Txn.group_index() == Int(num_of_transactions - 1)), # The method call is after its args.
         # https://developer.algorand.org/docs/get-details/dapps/avm/teal/guidelines/,
         Assert(check_rekey_zero(num_of_transactions)),
         Int(1),
     )
 
 def validate_amounts_for_first_king(adminFeeTx: TxnObject, compensationTx: TxnObject) -> Expr:
+    """The compensation also funds the min balance of the crown, like in the other claims"""
     return Seq(
-            Assert(adminFeeTx.amount() + compensationTx.amount() == App.globalGet(init_price_key)),
+            Assert(adminFeeTx.amount() + compensationTx.amount() == App.globalGet(init_price_key) + crown_min_balance),
             Assert(adminFeeTx.amount() == calculate_admin_fee_from_price(App.globalGet(init_price_key))),
             Int(1),
         )
 
 def assert_overthrowing_amounts(adminFeeTx: TxnObject, compensationTx: TxnObject, rewardTx: TxnObject) -> Expr:
     return Seq(
-            Assert(adminFeeTx.amount() + compensationTx.amount() + rewardTx.amount() == App.globalGet(king_price_key)),
+            Assert(adminFeeTx.amount() + compensationTx.amount() + rewardTx.amount() == App.globalGet(king_price_key) + crown_min_balance),
             Assert(rewardTx.amount() == calculate_reward_from_price(App.globalGet(king_price_key))),
             Assert(adminFeeTx.amount() == calculate_admin_fee_from_price(App.globalGet(king_price_key))),
         )
 
 def assert_end_of_reign_amounts(adminFeeTx: TxnObject, compensationTx: TxnObject, rewardTx: TxnObject) -> Expr:
     return Seq(
-            Assert(adminFeeTx.amount() + compensationTx.amount() + rewardTx.amount() == App.globalGet(init_price_key)),
+            Assert(adminFeeTx.amount() + compensationTx.amount() + rewardTx.amount() == App.globalGet(init_price_key) + crown_min_balance),
             Assert(rewardTx.amount() == calculate_reward_from_price(App.globalGet(init_price_key))),
             Assert(adminFeeTx.amount() == calculate_admin_fee_from_price(App.globalGet(init_price_key))),
         )
@@ -178,7 +454,63 @@ def set_new_king() -> Expr:
     return Seq(
         scratchPrice.store(App.globalGet(king_price_key)),
         App.globalPut(king_price_key, scratchPrice.load()* king_price_multiplier),
-        App.globalPut(king_address_key, Gtxn[2].sender()),
+        App.globalPut(king_address_key, Gtxn[1].sender()),
+        App.globalPut(reign_key, App.globalGet(reign_key) + Int(1)),
+        mint_crown(scratchPrice.load()),
+    )
+
+@Subroutine(TealType.none)
+def mint_crown(price) -> Expr:
+    """Creates the crown of the new king with its ARC-69 metadata, the app account holds it until the king collects it.
+    The message, the string arg of the claim, is a JSON string encoded by the client. A bad encoding only spoils
+    the claimant's own crown and the reign and price come after it, so it can't override them."""
+    message = If(Len(Txn.application_args[1]) > Int(2), Suffix(Txn.application_args[1], Int(2)), Bytes('""')) # After the ABI length.
+
+    return Seq(
+        Assert(Len(message) <= max_message_length),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.AssetConfig,
+                TxnField.config_asset_total: Int(1),
+                TxnField.config_asset_decimals: Int(0),
+                TxnField.config_asset_default_frozen: Int(0),
+                TxnField.config_asset_unit_name: Bytes("CROWN"),
+                TxnField.config_asset_name: Bytes("King of Algo Crown"),
+                TxnField.config_asset_manager: Global.current_application_address(), # To burn it.
+                TxnField.config_asset_reserve: Gtxn[1].sender(), # The king who can collect it.
+                TxnField.note: Concat(
+                    Bytes('{"standard":"arc69","description":"King of Algo crown","properties":{"message":'),
+                    message,
+                    Bytes(',"reign":'),
+                    itoa(App.globalGet(reign_key)),
+                    Bytes(',"price":'),
+                    itoa(price),
+                    Bytes('}}'),
+                ),
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        App.globalPut(crown_key, InnerTxn.created_asset_id()),
+        App.globalPut(crowns_key, App.globalGet(crowns_key) + Int(1)),
+    )
+
+@Subroutine(TealType.bytes)
+def itoa(i) -> Expr:
+    """Returns the decimal representation of the uint64"""
+    n = ScratchVar(TealType.uint64)
+    result = ScratchVar(TealType.bytes)
+
+    return Seq(
+        If(i == Int(0)).Then(Return(Bytes("0"))),
+        n.store(i),
+        result.store(Bytes("")),
+        While(n.load() > Int(0)).Do(
+            result.store(Concat(Extract(Itob(n.load() % Int(10) + Int(48)), Int(7), Int(1)), result.load())),
+            n.store(n.load() / Int(10)),
+        ),
+        result.load(),
     )
 
 @Subroutine(TealType.none)
@@ -187,6 +519,7 @@ def set_init_state() -> Expr:
         App.globalPut(king_address_key, empty_str),
         App.globalPut(init_price_key, initial_king_price),
         App.globalPut(king_price_key, initial_king_price), # Has to be > 0
+        apply_pending_params(), # Before the timestamp, the new reign period applies to the new reign.
         reset_timestamp(),
     )
 
@@ -207,15 +540,20 @@ def set_reward_multiplier() -> Expr:
     return App.globalPut(reward_multiplier_key, reward_multiplier_fp)
 
 def set_period() -> Expr:
-    return App.globalPut(reign_period_key, Btoi(Txn.application_args[0]))
+    return App.globalPut(reign_period_key, Btoi(Txn.application_args[1]))
 
 def assert_fee_for_inner_tx() -> Expr:
-    """The new king has to pay for the tx fee of the inner tx to the previous king"""
-    return Seq(   
-        Assert(Txn.fee() == Global.min_txn_fee() * Int(2)),
+    """The new king has to pay for the tx fee of the inner tx to the previous king, and of the inner tx minting the crown.
+    A congested network or a pooled fee can take more, the client caps it."""
+    return Seq(
+        Assert(Txn.fee() >= Global.min_txn_fee() * Int(3)),
     )
 
 def send_compensation_to_the_dead_king(receiver: TxnExpr, amount: Int) -> Expr:
+    return send_payment(receiver, amount)
+
+def send_payment(receiver: Expr, amount: Expr) -> Expr:
+    """Pays from the app account, the caller pays for the fee of the inner tx"""
     return Seq(
         InnerTxnBuilder.Begin(),
         InnerTxnBuilder.SetFields(
@@ -229,6 +567,22 @@ def send_compensation_to_the_dead_king(receiver: TxnExpr, amount: Int) -> Expr:
         InnerTxnBuilder.Submit(),
     )
 
+def close_app_account_to(receiver: Expr) -> Expr:
+    """The admin pays for the fee of the inner txs when deleting the app"""
+    return Seq(
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.Payment,
+                TxnField.receiver: receiver,
+                TxnField.amount: Int(0),
+                TxnField.close_remainder_to: receiver,
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+    )
+
 def get_last_king_compensation() -> Expr:
     return Minus(Balance(Global.current_application_address()), MinBalance(Global.current_application_address()))
 
@@ -261,6 +615,9 @@ def div_ceil(a, b) -> Expr:
     q = a / b
     return If(a % b > Int(0), q + Int(1), q)
 
+def abi_return_uint64(value: Expr) -> Expr:
+    return Log(Concat(abi_return_prefix, Itob(value)))
+
 def check_rekey_zero(num_transactions) -> Expr:
     return And(*[
                 Gtxn[i].rekey_to() == Global.zero_address()
@@ -279,4 +636,7 @@ if __name__ == "__main__":
         f.write(approval_program())
 
     with open(os.path.join(path, "clear.teal"), "w") as f:
-        f.write(clear_state_program())
\ No newline at end of file
+        f.write(clear_state_program())
+
+    write_contract(os.path.join(path, "contract.json"), king_of_algo_contract())
+    write_contract(os.path.join(path, "KingOfAlgo.arc56.json"), app_spec(king_of_algo_contract(), king_of_algo_keys()))
\ No newline at end of file
@@ -0,0 +1,264 @@
+import os
+from pyteal import *
+from king_of_algo import *
+from arc4 import app_spec, king_of_algo_asa_contract, king_of_algo_asa_keys, write_contract
+
+"""King Of Algo priced in an ASA: the admin fee, compensation and reward are transfers of the asset"""
+
+asset_key = Bytes("asset")
+
+def approval_program_asa():
+    handle_optin = Reject()
+    handle_closeout = Reject()
+
+    program = Cond(
+        [Txn.application_id() == Int(0), handle_creation_asa()],
+        [Txn.on_completion() == OnComplete.OptIn, handle_optin],
+        [Txn.on_completion() == OnComplete.CloseOut, handle_closeout],
+        [Txn.on_completion() == OnComplete.UpdateApplication, handle_update()],
+        [Txn.on_completion() == OnComplete.DeleteApplication, handle_delete_asa()],
+        [Txn.on_completion() == OnComplete.NoOp, handle_noop_asa()]
+    )
+    return compileTeal(program, Mode.Application, version=6)
+
+def handle_creation_asa() -> Expr:
+    """create(uint64,uint64,uint64,uint64)void: the reign period, asset ID, init price in base units of the asset and upgrade delay"""
+    return Seq(
+        Assert(Txn.application_args[0] == MethodSignature("create(uint64,uint64,uint64,uint64)void")),
+        set_admin(),
+        set_admin_fee(),
+        set_period(),
+        set_reward_multiplier(),
+        App.globalPut(asset_key, Btoi(Txn.application_args[2])),
+        App.globalPut(init_price_key, Btoi(Txn.application_args[3])),
+        Assert(App.globalGet(init_price_key) > Int(0)),
+        App.globalPut(upgrade_delay_key, Btoi(Txn.application_args[4])),
+        App.globalPut(reign_key, Int(0)),
+        set_init_state_asa(),
+        Approve()
+    )
+
+def handle_noop_asa() -> Expr:
+    return Cond(
+        [Txn.application_args[0] == MethodSignature("opt_in_asset()void"), handle_asset_optin()],
+        # The group of a claim depends on the king, handle_claim_asa checks it matches.
+        [
+            Or(
+                Txn.application_args[0] == MethodSignature("claim_throne(axfer,axfer,axfer)void"),
+                Txn.application_args[0] == MethodSignature("claim_empty_throne(axfer,axfer)void"),
+            ),
+            handle_claim_asa()
+        ],
+        [Txn.application_args[0] == MethodSignature("abdicate()uint64"), handle_abdicate_asa()],
+        *admin_methods(),
+    )
+
+def handle_asset_optin() -> Expr:
+    """The app account has to hold the asset before the first claim, the admin pays for the fee of the inner tx"""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.AssetTransfer,
+                TxnField.xfer_asset: App.globalGet(asset_key),
+                TxnField.asset_receiver: Global.current_application_address(),
+                TxnField.asset_amount: Int(0),
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        Approve()
+    )
+
+def handle_delete_asa() -> Expr:
+    """Settles the current king's compensation, closes the asset holding and the app account to the admin"""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(admin_address_key)),
+        assert_delete_announced(),
+        # A king who opted out of the asset can't receive it, its compensation goes to the admin with the rest.
+        If(App.globalGet(king_address_key) != empty_str).Then(
+            If(king_holds_asset()).Then(
+                send_asset_compensation_to_the_dead_king(App.globalGet(king_address_key)),
+            ),
+        ),
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.AssetTransfer,
+                TxnField.xfer_asset: App.globalGet(asset_key),
+                TxnField.asset_receiver: App.globalGet(admin_address_key),
+                TxnField.asset_amount: Int(0),
+                TxnField.asset_close_to: App.globalGet(admin_address_key),
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+        close_app_account_to(App.globalGet(admin_address_key)),
+        Approve()
+    )
+
+def handle_abdicate_asa() -> Expr:
+    """Like handle_abdicate, the compensation is in the asset"""
+    return Seq(
+        Assert(Txn.sender() == App.globalGet(king_address_key)),
+        Assert(Txn.fee() >= Global.min_txn_fee() * Int(2)),
+        send_asset_compensation_to_the_dead_king(Txn.sender()),
+        abi_return_uint64(InnerTxn.asset_amount()),
+        set_init_state_asa(),
+        Approve()
+    )
+
+def handle_claim_asa() -> Expr:
+    return Seq(
+        assert_not_paused(),
+        Cond(
+            [App.globalGet(king_address_key) == empty_str, handle_when_is_the_first_king_asa()],
+            [App.globalGet(king_address_key) != empty_str, handle_when_king_is_set_asa()]
+        )
+    )
+
+def handle_when_is_the_first_king_asa() -> Expr:
+    """claim_empty_throne: the transfers are the args of the method, before the app call"""
+    adminFeeTx = Gtxn[0]
+    compensationTx = Gtxn[1]
+    appTx = Gtxn[2]
+
+    return Cond(
+        [
+            And(
+                validate_tx_group(3),
+                validate_app_tx(appTx),
+                validate_asset_tx(adminFeeTx, App.globalGet(admin_address_key)),
+                validate_asset_tx(compensationTx, Global.current_application_address()),
+                Seq(
+                    Assert(adminFeeTx.asset_amount() + compensationTx.asset_amount() == App.globalGet(init_price_key)),
+                    Assert(adminFeeTx.asset_amount() == calculate_admin_fee_from_price(App.globalGet(init_price_key))),
+                    Int(1),
+                ),
+            ),
+            Seq(
+                reset_timestamp(),
+                set_new_king_asa(),
+                Approve()
+            )
+        ]
+    )
+
+def handle_when_king_is_set_asa() -> Expr:
+    """claim_throne: the transfers are the args of the method, before the app call"""
+    adminFeeTx = Gtxn[0]
+    compensationTx = Gtxn[1]
+    rewardTx = Gtxn[2]
+    appTx = Gtxn[3]
+
+    return Cond(
+        [
+            And(
+                validate_tx_group(4),
+                validate_app_tx(appTx),
+                validate_asset_tx(adminFeeTx, App.globalGet(admin_address_key)),
+                validate_asset_tx(compensationTx, Global.current_application_address()),
+                validate_asset_tx(rewardTx, reward_receiver()),
+            ),
+            Seq(
+                If(App.globalGet(end_of_reign_timestamp_key) > Global.latest_timestamp())
+                .Then(
+                    assert_asset_amounts(adminFeeTx, compensationTx, rewardTx, App.globalGet(king_price_key)),
+                )
+                .Else(
+                    Assert(Txn.fee() >= Global.min_txn_fee() * Int(2)), # The inner tx to the dead king.
+                    apply_pending_params(), # The claim starts the next reign, it pays with its params.
+                    assert_asset_amounts(adminFeeTx, compensationTx, rewardTx, App.globalGet(init_price_key)),
+                    # The compensation of a king who opted out stays in the app account, like its reward.
+                    If(rewardTx.asset_receiver() != Global.current_application_address()).Then(
+                        send_asset_compensation_to_the_dead_king(rewardTx.asset_receiver()),
+                    ),
+                    set_init_state_asa(),
+                ),
+                set_new_king_asa(),
+                Approve()
+            )
+        ]
+    )
+
+def validate_asset_tx(tx: TxnObject, receiver: Expr) -> Expr:
+    """A transfer of the asset from the claimant, clawbacks aren't accepted"""
+    return Seq(
+        Assert(tx.type_enum() == TxnType.AssetTransfer),
+        Assert(tx.xfer_asset() == App.globalGet(asset_key)),
+        Assert(tx.sender() != App.globalGet(king_address_key)),
+        Assert(tx.asset_receiver() == receiver),
+        Assert(tx.asset_close_to() == Global.zero_address()),
+        Assert(tx.asset_sender() == Global.zero_address()),
+        Int(1),
+    )
+
+def reward_receiver() -> Expr:
+    """The reward goes to the king, or to the app account when the king opted out of the asset so it can't block the claims"""
+    return If(king_holds_asset(), App.globalGet(king_address_key), Global.current_application_address())
+
+@Subroutine(TealType.uint64)
+def king_holds_asset() -> Expr:
+    """The king has to be in the foreign accounts of the call"""
+    holding = AssetHolding.balance(App.globalGet(king_address_key), App.globalGet(asset_key))
+
+    return Seq(holding, holding.hasValue())
+
+def assert_asset_amounts(adminFeeTx: TxnObject, compensationTx: TxnObject, rewardTx: TxnObject, price: Expr) -> Expr:
+    return Seq(
+            Assert(adminFeeTx.asset_amount() + compensationTx.asset_amount() + rewardTx.asset_amount() == price),
+            Assert(rewardTx.asset_amount() == calculate_reward_from_price(price)),
+            Assert(adminFeeTx.asset_amount() == calculate_admin_fee_from_price(price)),
+        )
+
+@Subroutine(TealType.none)
+def set_new_king_asa() -> Expr:
+    """The thrones priced in an ASA don't award crowns, they count the reigns like the others"""
+    scratchPrice = ScratchVar(TealType.uint64)
+
+    return Seq(
+        scratchPrice.store(App.globalGet(king_price_key)),
+        App.globalPut(king_price_key, scratchPrice.load()* king_price_multiplier),
+        App.globalPut(king_address_key, Gtxn[1].sender()),
+        App.globalPut(reign_key, App.globalGet(reign_key) + Int(1)),
+    )
+
+@Subroutine(TealType.none)
+def set_init_state_asa() -> Expr:
+    """The init price is set at creation, it depends on the decimals of the asset"""
+    return Seq(
+        App.globalPut(king_address_key, empty_str),
+        App.globalPut(king_price_key, App.globalGet(init_price_key)),
+        apply_pending_params(),
+        reset_timestamp(),
+    )
+
+@Subroutine(TealType.none)
+def send_asset_compensation_to_the_dead_king(receiver: Expr) -> Expr:
+    """The app account holds the compensations in the asset, all of it goes to the dead king"""
+    balance = AssetHolding.balance(Global.current_application_address(), App.globalGet(asset_key))
+
+    return Seq(
+        balance,
+        InnerTxnBuilder.Begin(),
+        InnerTxnBuilder.SetFields(
+            {
+                TxnField.type_enum: TxnType.AssetTransfer,
+                TxnField.xfer_asset: App.globalGet(asset_key),
+                TxnField.asset_receiver: receiver, # The receiver must be in the foreign accounts and the asset in the foreign assets.
+                TxnField.asset_amount: balance.value(),
+                TxnField.fee: Int(0),
+            }
+        ),
+        InnerTxnBuilder.Submit(),
+    )
+
+if __name__ == "__main__":
+    path = os.path.dirname(os.path.abspath(__file__))
+
+    with open(os.path.join(path,"approval_asa.teal"), "w") as f:
+        f.write(approval_program_asa())
+
+    write_contract(os.path.join(path, "contract_asa.json"), king_of_algo_asa_contract())
+    write_contract(os.path.join(path, "KingOfAlgoASA.arc56.json"), app_spec(king_of_algo_asa_contract(), king_of_algo_asa_keys()))
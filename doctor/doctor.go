@@ -0,0 +1,395 @@
+// Package doctor diagnoses the environment of the client: node, network, indexer, account and app.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+	Skip Status = "skip"
+)
+
+// maxTimeSinceLastRound is how long without a new round before the node is considered stalled.
+const maxTimeSinceLastRound = time.Minute
+
+// Result is the outcome of one check and how to fix it.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// Report is the outcome of all the checks.
+type Report struct {
+	Network string    `json:"network"`
+	AppID   uint64    `json:"appId"`
+	Account string    `json:"account,omitempty"`
+	Time    time.Time `json:"time"`
+	Results []Result  `json:"results"`
+}
+
+// OK tells if no check failed.
+func (r Report) OK() bool {
+	for _, result := range r.Results {
+		if result.Status == Fail {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WriteText writes the report for humans.
+func (r Report) WriteText(w io.Writer) error {
+	header := fmt.Sprintf("network %s, app %d", r.Network, r.AppID)
+	if r.Account != "" {
+		header += ", account " + r.Account
+	}
+
+	_, err := fmt.Fprintf(w, "%s\n\n", header)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range r.Results {
+		_, err = fmt.Fprintf(w, "[%s] %s: %s\n", result.Status, result.Name, result.Message)
+		if err != nil {
+			return err
+		}
+
+		if result.Fix != "" && result.Status != Pass {
+			_, err = fmt.Fprintf(w, "       fix: %s\n", result.Fix)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the report to attach to support tickets.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+// doctor holds what the checks found so far, later checks skip when what they need is missing.
+type doctor struct {
+	cfg         *client.Config
+	profile     client.Profile
+	accountName string
+
+	report Report
+
+	algod  *algod.Client
+	params *types.SuggestedParams
+	state  *client.State
+}
+
+// Run runs all the checks of the network in use. The account name is optional, it defaults to the player account.
+func Run(ctx context.Context, cfg *client.Config, accountName string) Report {
+	d := &doctor{
+		cfg:         cfg,
+		profile:     cfg.Profile(),
+		accountName: accountName,
+		report: Report{
+			Network: cfg.Network,
+			AppID:   cfg.Profile().APPID,
+			Time:    time.Now().UTC(),
+		},
+	}
+
+	checks := []struct {
+		name  string
+		check func(ctx context.Context) Result
+	}{
+		{"algod", d.checkAlgod},
+		{"genesis", d.checkGenesis},
+		{"sync", d.checkSync},
+		{"indexer", d.checkIndexer},
+		{"app", d.checkApp},
+		{"program", d.checkProgram},
+		{"app account", d.checkAppAccount},
+		{"account balance", d.checkAccountBalance},
+	}
+
+	for _, c := range checks {
+		result := c.check(ctx)
+		result.Name = c.name
+		d.report.Results = append(d.report.Results, result)
+	}
+
+	return d.report
+}
+
+func (d *doctor) checkAlgod(ctx context.Context) Result {
+	algodClient, err := client.MakeAlgodClient(d.profile)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error(), Fix: "set a valid algod endpoint in the profile"}
+	}
+
+	params, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		fix := "check the algod endpoint is reachable"
+		if isUnauthorized(err) {
+			fix = "check the algod API token of the profile"
+		}
+
+		return Result{Status: Fail, Message: err.Error(), Fix: fix}
+	}
+
+	d.algod = algodClient
+	d.params = &params
+
+	return Result{Status: Pass, Message: fmt.Sprintf("%s answers", d.profile.Algod.Endpoint)}
+}
+
+func (d *doctor) checkGenesis(ctx context.Context) Result {
+	if d.params == nil {
+		return Result{Status: Skip, Message: "algod is unavailable"}
+	}
+
+	if d.profile.GenesisID == "" && d.profile.GenesisHash == "" {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf("the profile doesn't pin a genesis, the node is on %s", d.params.GenesisID),
+			Fix:     "set genesisid and genesishash in the profile",
+		}
+	}
+
+	err := d.profile.CheckNode(ctx, d.algod)
+	if err != nil {
+		// Nothing can be signed, the other checks would only add noise.
+		d.algod = nil
+
+		return Result{Status: Fail, Message: err.Error(), Fix: "point the profile to a node of its network or select another network"}
+	}
+
+	return Result{Status: Pass, Message: d.params.GenesisID}
+}
+
+func (d *doctor) checkSync(ctx context.Context) Result {
+	if d.algod == nil {
+		return Result{Status: Skip, Message: "algod is unavailable"}
+	}
+
+	status, err := d.algod.Status().Do(ctx)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error()}
+	}
+
+	if status.CatchupTime > 0 {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("catching up, at round %d", status.LastRound),
+			Fix:     "wait for the node to catch up or use fast catchup",
+		}
+	}
+
+	sinceLastRound := time.Duration(status.TimeSinceLastRound)
+	if sinceLastRound > maxTimeSinceLastRound {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf("no new round for %s", sinceLastRound.Round(time.Second)),
+			Fix:     "check the node is connected to its peers",
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("round %d", status.LastRound)}
+}
+
+func (d *doctor) checkIndexer(ctx context.Context) Result {
+	if d.profile.Indexer.Endpoint == "" {
+		return Result{Status: Warn, Message: "no indexer in the profile", Fix: "set the indexer endpoint to read the history"}
+	}
+
+	indexerClient, err := client.MakeIndexerClient(d.profile)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error(), Fix: "set a valid indexer endpoint in the profile"}
+	}
+
+	health, err := indexerClient.HealthCheck().Do(ctx)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error(), Fix: "check the indexer endpoint and token"}
+	}
+
+	if !health.DbAvailable {
+		return Result{Status: Fail, Message: "the indexer database is unavailable", Fix: "check the indexer database"}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("round %d", health.Round)}
+}
+
+func (d *doctor) checkApp(ctx context.Context) Result {
+	if d.algod == nil {
+		return Result{Status: Skip, Message: "algod is unavailable"}
+	}
+
+	if d.profile.APPID == 0 {
+		return Result{Status: Fail, Message: "no app ID", Fix: "set appid or appname in the profile"}
+	}
+
+	app, err := d.algod.GetApplicationByID(d.profile.APPID).Do(ctx)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error(), Fix: "check the app ID, the app may be deleted or on another network"}
+	}
+
+	state, err := client.FormatStateStrict(app.Params.GlobalState)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error(), Fix: "the app may run another contract version, run koa verify"}
+	}
+
+	d.state = &state
+
+	err = state.Validate()
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error(), Fix: "the app state breaks the contract invariants, don't claim it"}
+	}
+
+	king := state.King
+	if king == "" {
+		king = "nobody"
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("king %s, price %d, end of reign %s", king, state.KingPrice, state.EndOfReign.UTC())}
+}
+
+func (d *doctor) checkProgram(ctx context.Context) Result {
+	if d.state == nil {
+		return Result{Status: Skip, Message: "the app is unavailable"}
+	}
+
+	// The admin of the config, when there is one, may have taken over from the creator.
+	_, admin, err := d.cfg.AccountAddress("", client.RoleAdmin)
+	if err != nil {
+		admin = ""
+	}
+
+	verification, err := client.VerifyApp(ctx, d.algod, d.profile.APPID, contracts.Version, "", admin)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error()}
+	}
+
+	if !verification.AdminMatch && verification.ApprovalMatch && verification.ClearMatch && verification.SchemaMatch {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf("matches the %s contract but the admin %s isn't the expected one, the creator is %s", contracts.Version, verification.Admin, verification.Creator),
+			Fix:     "check the admin was handed over with koa verify -admin",
+		}
+	}
+
+	if verification.Detected != "" && verification.Detected != contracts.Version {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("the app runs the %s contract, not the %s one", verification.Detected, contracts.Version),
+			Fix:     "deploy an app of the current contract with koa deploy",
+		}
+	}
+
+	if !verification.OK() {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("the app doesn't run the %s contract", contracts.Version),
+			Fix:     "run koa verify to see the differences",
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("matches the %s contract", contracts.Version)}
+}
+
+func (d *doctor) checkAppAccount(ctx context.Context) Result {
+	if d.state == nil {
+		return Result{Status: Skip, Message: "the app is unavailable"}
+	}
+
+	appAddress := crypto.GetApplicationAddress(d.profile.APPID).String()
+	info, err := client.GetAccountBalance(ctx, d.algod, appAddress)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error()}
+	}
+
+	// The node counts the asset the app account holds and the crowns it created in its min balance.
+	if info.Amount < info.MinBalance {
+		return Result{
+			Status:  Fail,
+			Message: fmt.Sprintf("%s holds %d, below its min balance %d", appAddress, info.Amount, info.MinBalance),
+			Fix:     fmt.Sprintf("send %d to %s", info.MinBalance-info.Amount, appAddress),
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("%s holds %d, its min balance is %d", appAddress, info.Amount, info.MinBalance)}
+}
+
+func (d *doctor) checkAccountBalance(ctx context.Context) Result {
+	name, address, err := d.cfg.AccountAddress(d.accountName, client.RolePlayer)
+	if err != nil {
+		return Result{Status: Warn, Message: err.Error(), Fix: "add a player account to the config or pass -account"}
+	}
+
+	d.report.Account = name
+
+	if d.state == nil || d.params == nil {
+		return Result{Status: Skip, Message: "the app is unavailable"}
+	}
+
+	info, err := client.GetAccountBalance(ctx, d.algod, address)
+	if err != nil {
+		return Result{Status: Fail, Message: err.Error()}
+	}
+
+	minFee := d.params.MinFee
+	if minFee == 0 {
+		minFee = transaction.MinTxnFee
+	}
+
+	// The account has to keep its own min balance after paying, the node counts its assets and apps in it.
+	// The claim funds the min balance of the crown it mints, and the crown raises the account's once collected.
+	needed := d.state.ClaimPrice() + 2*d.state.ClaimCrownFunding() + d.state.ClaimFees(minFee) + info.MinBalance
+	if d.state.AssetID != 0 {
+		// The price is paid in the asset, the account only pays the fees in ALGO.
+		needed = d.state.ClaimFees(minFee) + info.MinBalance
+
+		// The claimant holds the claim price of the asset and the accounts paid are opted in.
+		err = client.CheckAssetOptIns(ctx, d.algod, *d.state, address)
+		if err != nil {
+			return Result{Status: Warn, Message: err.Error(), Fix: fmt.Sprintf("opt in to asset %d and fund %s with %d of it", d.state.AssetID, address, d.state.ClaimPrice())}
+		}
+	}
+
+	if info.Amount < needed {
+		return Result{
+			Status:  Warn,
+			Message: fmt.Sprintf("%s holds %d, the next claim needs %d", address, info.Amount, needed),
+			Fix:     fmt.Sprintf("fund %s with at least %d", address, needed-info.Amount),
+		}
+	}
+
+	return Result{Status: Pass, Message: fmt.Sprintf("%s holds %d, the next claim needs %d", address, info.Amount, needed)}
+}
+
+func isUnauthorized(err error) bool {
+	// The SDK only tells the status code in the message.
+	return strings.HasPrefix(err.Error(), "HTTP 401")
+}
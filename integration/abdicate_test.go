@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAbdicate(t *testing.T) {
+	Convey("client.Abdicate()", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+		initPrice := state.InitPrice
+
+		_, err = client.BecomeKing(
+			s.Ctx,
+			s.Algod,
+			false,
+			client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+			3,
+		)
+		So(err, ShouldBeNil)
+
+		Convey("Pays the first king's compensation at once and resets the throne", func() {
+			beforeBalances := s.getAccountsBalances()
+
+			compensation, err := client.Abdicate(s.Ctx, s.Algod, first, appID, 3)
+			So(err, ShouldBeNil)
+			So(compensation, ShouldEqual, initPrice-multiplyPercentage(initPrice, state.AdminFee))
+
+			// The king pays for the fee of the app call and of the inner tx.
+			balances := s.getAccountsBalances()
+			So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+compensation-(transaction.MinTxnFee*2))
+			// The app account keeps the min balance of the crown minted by the claim.
+			So(s.getContractAccountInfo(appID).Amount, ShouldEqual, s.minBalance()+state.ClaimCrownFunding())
+
+			newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(newState.King, ShouldEqual, "")
+			So(newState.KingPrice, ShouldEqual, initPrice)
+			So(newState.EndOfReign, ShouldHappenAfter, time.Now())
+		})
+
+		Convey("Accepts a fee above the min fees, like a fee raised for congestion", func() {
+			beforeBalances := s.getAccountsBalances()
+
+			compensation, _, err := client.NewKingOfAlgoClient(s.Algod, appID).Abdicate(s.Ctx, first, client.CallParams{MinFees: 4, WaitRounds: 3})
+			So(err, ShouldBeNil)
+			So(compensation, ShouldEqual, initPrice-multiplyPercentage(initPrice, state.AdminFee))
+
+			balances := s.getAccountsBalances()
+			So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+compensation-(transaction.MinTxnFee*4))
+		})
+
+		Convey("Pays what is left after the reward when the king overthrew another one", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			kingPrice := state.KingPrice
+
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			beforeContractAccountInfo := s.getContractAccountInfo(appID)
+
+			compensation, err := client.Abdicate(s.Ctx, s.Algod, second, appID, 3)
+			So(err, ShouldBeNil)
+			So(compensation, ShouldEqual, beforeContractAccountInfo.Amount-s.minBalance()-state.ClaimCrownFunding()*2)
+
+			firstCompensation := initPrice - multiplyPercentage(initPrice, state.AdminFee)
+			secondCompensation := kingPrice - multiplyPercentage(kingPrice, state.AdminFee) - multiplyPercentage(kingPrice, state.RewardMultiplier)
+			So(compensation, ShouldEqual, firstCompensation+secondCompensation)
+		})
+
+		Convey("Refuses an account that isn't the king", func() {
+			_, err := client.Abdicate(s.Ctx, s.Algod, second, appID, 3)
+			So(errors.Is(err, client.ErrNotKing), ShouldBeTrue)
+		})
+	})
+}
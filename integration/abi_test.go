@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestContractABI(t *testing.T) {
+	Convey("ARC-4 contracts", t, func() {
+		for _, assetID := range []uint64{0, 1} {
+			contract, err := client.Contract(contracts.Version, assetID)
+			So(err, ShouldBeNil)
+
+			for _, name := range []string{"create", "claim_throne", "claim_empty_throne", "abdicate", "set_params", "announce_upgrade"} {
+				_, err := contract.GetMethodByName(name)
+				So(err, ShouldBeNil)
+			}
+		}
+
+		Convey("Decodes the global state described by the app specs", func() {
+			owner := types.Address{1}
+			rawState := []models.TealKeyValue{
+				{Key: base64.StdEncoding.EncodeToString([]byte("king")), Value: models.TealValue{Type: 1, Bytes: base64.StdEncoding.EncodeToString(owner[:])}},
+				{Key: base64.StdEncoding.EncodeToString([]byte("king_price")), Value: models.TealValue{Type: 2, Uint: 200000}},
+				{Key: base64.StdEncoding.EncodeToString([]byte("upgrade_hash")), Value: models.TealValue{Type: 1, Bytes: base64.StdEncoding.EncodeToString([]byte("delete"))}},
+			}
+
+			global, err := client.DecodeAppGlobalState(rawState)
+			So(err, ShouldBeNil)
+			So(global.King, ShouldEqual, owner.String())
+			So(global.KingPrice, ShouldEqual, 200000)
+			So(global.UpgradeHash, ShouldResemble, []byte("delete"))
+
+			state, err := client.FormatState(rawState)
+			So(err, ShouldBeNil)
+			So(state.King, ShouldEqual, owner.String())
+			So(state.PendingUpgrade.Delete, ShouldBeTrue)
+		})
+
+		Convey("Collects the crown minted by the claim with collect_crown", func() {
+			s := NewSuite(t)
+
+			owner := s.Accounts[0]
+			first := s.Accounts[1]
+
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+			So(err, ShouldBeNil)
+
+			state, err := client.GetContractState(s.Ctx, s.Algod, owner, appID)
+			So(err, ShouldBeNil)
+
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king").LeaveCrown(),
+				3,
+			)
+			So(err, ShouldBeNil)
+			So(resp.Crown, ShouldNotEqual, 0)
+
+			state, err = client.GetContractState(s.Ctx, s.Algod, owner, appID)
+			So(err, ShouldBeNil)
+			So(state.King, ShouldEqual, first.Address.String())
+			So(state.Crown, ShouldEqual, resp.Crown)
+
+			err = client.CollectCrown(s.Ctx, s.Algod, first, appID, resp.Crown, 3)
+			So(err, ShouldBeNil)
+			So(s.getAssetBalance(first.Address.String(), resp.Crown), ShouldEqual, 1)
+		})
+	})
+}
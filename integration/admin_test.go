@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAdminRotation(t *testing.T) {
+	Convey("Admin rotation", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		err = client.ProposeAdmin(s.Ctx, s.Algod, owner, appID, second.Address.String(), 3)
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+		So(state.Admin, ShouldEqual, owner.Address.String())
+		So(state.PendingAdmin, ShouldEqual, second.Address.String())
+
+		Convey("Makes the pending admin the admin once it accepts", func() {
+			err := client.AcceptAdmin(s.Ctx, s.Algod, second, appID, 3)
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Admin, ShouldEqual, second.Address.String())
+			So(state.PendingAdmin, ShouldEqual, "")
+
+			Convey("And the admin fee of the next claim goes to the new admin", func() {
+				beforeBalances := s.getAccountsBalances()
+
+				_, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+					3,
+				)
+				So(err, ShouldBeNil)
+
+				balances := s.getAccountsBalances()
+				adminFee := multiplyPercentage(state.InitPrice, state.AdminFee)
+				So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]+adminFee)
+				So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()])
+			})
+
+			Convey("And the previous admin can't propose anymore", func() {
+				err := client.ProposeAdmin(s.Ctx, s.Algod, owner, appID, owner.Address.String(), 3)
+				So(errors.Is(err, client.ErrNotAdmin), ShouldBeTrue)
+			})
+		})
+
+		Convey("Refuses an account that isn't the pending admin", func() {
+			err := client.AcceptAdmin(s.Ctx, s.Algod, first, appID, 3)
+			So(err, ShouldNotBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Admin, ShouldEqual, owner.Address.String())
+		})
+
+		Convey("Removes the pending admin when cancelled", func() {
+			err := client.CancelAdminProposal(s.Ctx, s.Algod, owner, appID, 3)
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.PendingAdmin, ShouldEqual, "")
+
+			err = client.AcceptAdmin(s.Ctx, s.Algod, second, appID, 3)
+			So(errors.Is(err, client.ErrNoPendingAdmin), ShouldBeTrue)
+		})
+	})
+}
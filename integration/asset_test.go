@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestASAThrone(t *testing.T) {
+	Convey("Throne priced in an ASA", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		initPrice := uint64(1000)
+		assetID := s.createAsset(owner, 1000000)
+		s.optInAsset(first, assetID)
+		s.transferAsset(owner, first, assetID, 100000)
+
+		appID, err := client.DeployASA(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, assetID, initPrice, "")
+		So(err, ShouldBeNil)
+
+		appAddress := crypto.GetApplicationAddress(appID).String()
+
+		Convey("Opts the app account in and sets the state", func() {
+			state, err := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(err, ShouldBeNil)
+			So(state.AssetID, ShouldEqual, assetID)
+			So(state.InitPrice, ShouldEqual, initPrice)
+			So(state.KingPrice, ShouldEqual, initPrice)
+			So(state.Validate(), ShouldBeNil)
+
+			So(s.getAssetBalance(appAddress, assetID), ShouldEqual, 0)
+
+			// The asset holding raises the min balance of the app account.
+			balance, err := client.GetAccountBalance(s.Ctx, s.Algod, appAddress)
+			So(err, ShouldBeNil)
+			So(balance.MinBalance, ShouldEqual, client.MinBalance*2)
+			So(balance.Amount, ShouldBeGreaterThanOrEqualTo, balance.MinBalance)
+
+			rawState, err := client.ReadGlobalState(s.Ctx, s.Algod, owner.Address.String(), appID)
+			So(err, ShouldBeNil)
+
+			strictState, err := client.FormatStateStrict(rawState)
+			So(err, ShouldBeNil)
+			So(strictState.AssetID, ShouldEqual, assetID)
+
+			// The keys of the other variant don't belong in the state of an ASA throne.
+			crown := models.TealKeyValue{Key: base64.StdEncoding.EncodeToString([]byte("crown")), Value: models.TealValue{Type: 2}}
+			_, err = client.FormatStateStrict(append(rawState, crown))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "unexpected global state key \"crown\"")
+
+			verification, err := client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, owner.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(verification.OK(), ShouldBeTrue)
+		})
+
+		Convey("Pays the first king's price in the asset", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			ownerBefore := s.getAssetBalance(owner.Address.String(), assetID)
+
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			adminFee := multiplyPercentage(initPrice, state.AdminFee)
+			So(s.getAssetBalance(first.Address.String(), assetID), ShouldEqual, 100000-initPrice)
+			So(s.getAssetBalance(owner.Address.String(), assetID), ShouldEqual, ownerBefore+adminFee)
+			So(s.getAssetBalance(appAddress, assetID), ShouldEqual, initPrice-adminFee)
+
+			// The reign keys the lease of the claims, like in the throne priced in ALGO.
+			newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(newState.Reign, ShouldEqual, state.Reign+1)
+
+			Convey("And the second king rewards the first one in the asset", func() {
+				s.optInAsset(second, assetID)
+				s.transferAsset(owner, second, assetID, 100000)
+
+				state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+				So(state.King, ShouldEqual, first.Address.String())
+
+				_, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+					3,
+				)
+				So(err, ShouldBeNil)
+
+				reward := multiplyPercentage(state.KingPrice, state.RewardMultiplier)
+				So(s.getAssetBalance(first.Address.String(), assetID), ShouldEqual, 100000-initPrice+reward)
+				So(s.getAssetBalance(second.Address.String(), assetID), ShouldEqual, 100000-state.KingPrice)
+			})
+
+			Convey("And the decommission pays the king's compensation in the asset", func() {
+				_, err := client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldBeNil)
+
+				report, err := client.InspectDecommission(s.Ctx, s.Algod, appID)
+				So(err, ShouldBeNil)
+				So(report.AssetID, ShouldEqual, assetID)
+				So(report.Compensation, ShouldEqual, initPrice-adminFee)
+				So(report.AssetRemainder, ShouldEqual, 0)
+				// The ALGO of the app account only fund its min balance, they go to the admin.
+				So(report.Remainder, ShouldEqual, s.getContractAccountInfo(appID).Amount)
+
+				firstBefore := s.getAssetBalance(first.Address.String(), assetID)
+
+				_, err = client.Decommission(s.Ctx, s.Algod, owner, appID, true, 3)
+				So(err, ShouldBeNil)
+				So(s.getAssetBalance(first.Address.String(), assetID), ShouldEqual, firstBefore+report.Compensation)
+			})
+
+			Convey("And a king who opts out of the asset doesn't block the throne", func() {
+				// The king closes its holding of the asset to the owner.
+				tx, err := transaction.MakeAssetTransferTxn(first.Address.String(), owner.Address.String(), 0, nil, s.getSuggestedParams(), owner.Address.String(), assetID)
+				So(err, ShouldBeNil)
+				s.sendTx(first, tx)
+
+				state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+				optedOut, err := client.KingOptedOut(s.Ctx, s.Algod, state)
+				So(err, ShouldBeNil)
+				So(optedOut, ShouldBeTrue)
+
+				Convey("The next claim sends the reward to the app account", func() {
+					s.optInAsset(second, assetID)
+					s.transferAsset(owner, second, assetID, 100000)
+					appBefore := s.getAssetBalance(appAddress, assetID)
+
+					_, err := client.BecomeKing(
+						s.Ctx,
+						s.Algod,
+						false,
+						client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+						3,
+					)
+					So(err, ShouldBeNil)
+
+					newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+					So(newState.King, ShouldEqual, second.Address.String())
+
+					adminFee := multiplyPercentage(state.KingPrice, state.AdminFee)
+					So(s.getAssetBalance(second.Address.String(), assetID), ShouldEqual, 100000-state.KingPrice)
+					So(s.getAssetBalance(appAddress, assetID), ShouldEqual, appBefore+state.KingPrice-adminFee)
+				})
+
+				Convey("The admin deletes the app and gets the compensation", func() {
+					_, err := client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+					So(err, ShouldBeNil)
+
+					ownerBefore := s.getAssetBalance(owner.Address.String(), assetID)
+					appBefore := s.getAssetBalance(appAddress, assetID)
+
+					report, err := client.InspectDecommission(s.Ctx, s.Algod, appID)
+					So(err, ShouldBeNil)
+					So(report.Compensation, ShouldEqual, 0)
+					So(report.AssetRemainder, ShouldEqual, appBefore)
+
+					_, err = client.Decommission(s.Ctx, s.Algod, owner, appID, true, 3)
+					So(err, ShouldBeNil)
+					So(s.getAssetBalance(owner.Address.String(), assetID), ShouldEqual, ownerBefore+appBefore)
+
+					_, err = s.Algod.GetApplicationByID(appID).Do(s.Ctx)
+					So(err, ShouldNotBeNil)
+				})
+			})
+		})
+
+		Convey("Refuses a claimant that isn't opted in", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the king"),
+				3,
+			)
+			So(errors.Is(err, client.ErrNotOptedIn), ShouldBeTrue)
+		})
+	})
+}
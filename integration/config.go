@@ -10,6 +10,8 @@ type config struct {
 	SandboxRepoPath string
 	AlgodEndpoint   string
 	AlgodToken      string
+	// KeystorePassphrase encrypts the generated accounts, they aren't saved without it.
+	KeystorePassphrase string
 }
 
 // newConfig returns a new configuration struct.
@@ -1,25 +1,28 @@
 package integration
 
 import (
-	"context"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestContractDeployment(t *testing.T) {
 	Convey("Contract deployment", t, func() {
-		s := NewSuite()
+		s := NewSuite(t)
 
 		owner := s.Accounts[0]
 		Convey("Creates app and sets default state", func() {
-			appID, err := client.Deploy(context.Background(), s.Algod, owner, time.Hour, "")
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
 			So(err, ShouldBeNil)
 
-			state, err := client.GetContractState(context.Background(), s.Algod, owner, appID)
+			state, err := client.GetContractState(s.Ctx, s.Algod, owner, appID)
 			So(err, ShouldBeNil)
 
 			So(state.EndOfReign.UTC(), ShouldHappenAfter, time.Now().UTC().Add(time.Minute*30))
@@ -31,12 +34,51 @@ func TestContractDeployment(t *testing.T) {
 			So(state.AdminFee, ShouldEqual, 5)
 			So(state.RewardMultiplier, ShouldEqual, 75)
 		})
+
+		Convey("Records the app in the registry", func() {
+			registry, err := client.LoadRegistry(filepath.Join(t.TempDir(), "apps.json"))
+			So(err, ShouldBeNil)
+
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, registry, "throne", time.Hour, 0, "a note")
+			So(err, ShouldBeNil)
+
+			params := s.getSuggestedParams()
+			genesisHash := base64.StdEncoding.EncodeToString(params.GenesisHash)
+
+			app, err := registry.Lookup(genesisHash, "throne")
+			So(err, ShouldBeNil)
+			So(app.AppID, ShouldEqual, appID)
+			So(app.Creator, ShouldEqual, owner.Address.String())
+			So(app.Version, ShouldEqual, contracts.Version)
+			So(app.ReignPeriod, ShouldEqual, time.Hour)
+			So(app.Note, ShouldEqual, "a note")
+			So(app.CreatedRound, ShouldBeGreaterThan, 0)
+
+			_, err = client.Deploy(s.Ctx, s.Algod, owner, registry, "throne", time.Hour, 0, "")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Deploys apps without a name without registering them", func() {
+			registry, err := client.LoadRegistry(filepath.Join(t.TempDir(), "apps.json"))
+			So(err, ShouldBeNil)
+
+			appID1, err := client.Deploy(s.Ctx, s.Algod, owner, registry, "", time.Hour, 0, "")
+			So(err, ShouldBeNil)
+
+			appID2, err := client.Deploy(s.Ctx, s.Algod, owner, registry, "", time.Hour, 0, "")
+			So(err, ShouldBeNil)
+			So(appID2, ShouldNotEqual, appID1)
+
+			params := s.getSuggestedParams()
+			_, err = registry.Lookup(base64.StdEncoding.EncodeToString(params.GenesisHash), "")
+			So(errors.Is(err, client.ErrAppNotFound), ShouldBeTrue)
+		})
 	})
 }
 
 func TestBecomeKing(t *testing.T) {
 	Convey("client.BecomeKing()", t, func() {
-		s := NewSuite()
+		s := NewSuite(t)
 
 		owner := s.Accounts[0]
 		first := s.Accounts[1]
@@ -50,17 +92,18 @@ func TestBecomeKing(t *testing.T) {
 		// 	fmt.Println("times up!")
 		// }()
 
-		appID, err := client.Deploy(context.Background(), s.Algod, owner, period, "")
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", period, 0, "")
 		So(err, ShouldBeNil)
 
 		Convey("Become first king when there is no previous king", func() {
-			state, _ := client.GetContractState(context.Background(), s.Algod, owner, appID)
+			state, _ := client.GetContractState(s.Ctx, s.Algod, owner, appID)
 
 			beforeBalances := s.getAccountsBalances()
 			priceToBeKing := state.KingPrice
+			crownFunding := state.ClaimCrownFunding()
 
 			_, err := client.BecomeKing(
-				context.Background(),
+				s.Ctx,
 				s.Algod,
 				false,
 				client.NewBecomeKingParams(
@@ -69,12 +112,12 @@ func TestBecomeKing(t *testing.T) {
 					state,
 					first,
 					"I am the first king",
-				),
+				).LeaveCrown(),
 				3,
 			)
 			So(err, ShouldBeNil)
 
-			state, err = client.GetContractState(context.Background(), s.Algod, owner, appID)
+			state, err = client.GetContractState(s.Ctx, s.Algod, owner, appID)
 			So(err, ShouldBeNil)
 
 			So(state.EndOfReign, ShouldHappenBefore, time.Now().Add(period))
@@ -88,19 +131,20 @@ func TestBecomeKing(t *testing.T) {
 			balances := s.getAccountsBalances()
 
 			So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+(multiplyPercentage(priceToBeKing, state.AdminFee)))
-			So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]-priceToBeKing-(transaction.MinTxnFee*3))
+			So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]-priceToBeKing-crownFunding-(transaction.MinTxnFee*4))
 			So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()])
 
 			Convey("Become second king", func() {
-				state, _ := client.GetContractState(context.Background(), s.Algod, owner, appID)
+				state, _ := client.GetContractState(s.Ctx, s.Algod, owner, appID)
 
 				beforeBalances := s.getAccountsBalances()
 				priceToBeKing := state.KingPrice
+				crownFunding := state.ClaimCrownFunding()
 
 				So(state.EndOfReign, ShouldHappenAfter, time.Now())
 
 				_, err := client.BecomeKing(
-					context.Background(),
+					s.Ctx,
 					s.Algod,
 					false,
 					client.NewBecomeKingParams(
@@ -109,13 +153,13 @@ func TestBecomeKing(t *testing.T) {
 						state,
 						second,
 						"I am the second king",
-					),
+					).LeaveCrown(),
 					3,
 				)
 
 				So(err, ShouldBeNil)
 
-				state, err = client.GetContractState(context.Background(), s.Algod, owner, appID)
+				state, err = client.GetContractState(s.Ctx, s.Algod, owner, appID)
 				So(err, ShouldBeNil)
 
 				So(state.InitPrice, ShouldEqual, 100000)
@@ -127,19 +171,20 @@ func TestBecomeKing(t *testing.T) {
 				balances := s.getAccountsBalances()
 
 				So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+(multiplyPercentage(priceToBeKing, state.AdminFee)))
-				So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]-priceToBeKing-(transaction.MinTxnFee*4))
+				So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]-priceToBeKing-crownFunding-(transaction.MinTxnFee*5))
 				So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+(multiplyPercentage(priceToBeKing, state.RewardMultiplier)))
 
 				Convey("Become third king", func() {
-					state, _ := client.GetContractState(context.Background(), s.Algod, owner, appID)
+					state, _ := client.GetContractState(s.Ctx, s.Algod, owner, appID)
 
 					beforeBalances := s.getAccountsBalances()
 					priceToBeKing := state.KingPrice
+					crownFunding := state.ClaimCrownFunding()
 
 					So(state.EndOfReign, ShouldHappenAfter, time.Now())
 
 					_, err := client.BecomeKing(
-						context.Background(),
+						s.Ctx,
 						s.Algod,
 						false,
 						client.NewBecomeKingParams(
@@ -148,12 +193,12 @@ func TestBecomeKing(t *testing.T) {
 							state,
 							first,
 							"I am the third king",
-						),
+						).LeaveCrown(),
 						3,
 					)
 					So(err, ShouldBeNil)
 
-					state, err = client.GetContractState(context.Background(), s.Algod, owner, appID)
+					state, err = client.GetContractState(s.Ctx, s.Algod, owner, appID)
 					So(err, ShouldBeNil)
 
 					So(state.InitPrice, ShouldEqual, 100000)
@@ -165,11 +210,11 @@ func TestBecomeKing(t *testing.T) {
 					balances := s.getAccountsBalances()
 
 					So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+(multiplyPercentage(priceToBeKing, state.AdminFee)))
-					So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]-priceToBeKing-(transaction.MinTxnFee*4))
+					So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]-priceToBeKing-crownFunding-(transaction.MinTxnFee*5))
 					So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]+(multiplyPercentage(priceToBeKing, state.RewardMultiplier)))
 
 					Convey("Returns an error for unbalanced rewards exploit", func() {
-						state, _ := client.GetContractState(context.Background(), s.Algod, owner, appID)
+						state, _ := client.GetContractState(s.Ctx, s.Algod, owner, appID)
 
 						beforeBalances := s.getAccountsBalances()
 
@@ -182,7 +227,7 @@ func TestBecomeKing(t *testing.T) {
 						So(state.EndOfReign, ShouldHappenBefore, time.Now())
 
 						_, err := client.BecomeKingUnbalancedRewardsExploit(
-							context.Background(),
+							s.Ctx,
 							s.Algod,
 							false,
 							client.NewBecomeKingParams(
@@ -196,7 +241,7 @@ func TestBecomeKing(t *testing.T) {
 						)
 						So(err, ShouldNotBeNil)
 
-						state, err = client.GetContractState(context.Background(), s.Algod, owner, appID)
+						state, err = client.GetContractState(s.Ctx, s.Algod, owner, appID)
 						So(err, ShouldBeNil)
 
 						So(state.InitPrice, ShouldEqual, 100000)
@@ -213,12 +258,13 @@ func TestBecomeKing(t *testing.T) {
 					})
 
 					Convey("Become king after end of reign", func() {
-						state, _ := client.GetContractState(context.Background(), s.Algod, owner, appID)
+						state, _ := client.GetContractState(s.Ctx, s.Algod, owner, appID)
 
 						beforeBalances := s.getAccountsBalances()
 						beforeContractAccountInfo := s.getContractAccountInfo(appID)
 
 						priceToBeKing := state.InitPrice
+						crownFunding := state.ClaimCrownFunding()
 
 						endOfReign := state.EndOfReign
 						timeLeft := endOfReign.Sub(time.Now())
@@ -229,7 +275,7 @@ func TestBecomeKing(t *testing.T) {
 						So(state.EndOfReign, ShouldHappenBefore, time.Now())
 
 						_, err := client.BecomeKing(
-							context.Background(),
+							s.Ctx,
 							s.Algod,
 							false,
 							client.NewBecomeKingParams(
@@ -238,12 +284,12 @@ func TestBecomeKing(t *testing.T) {
 								state,
 								second,
 								"I am the new king",
-							),
+							).LeaveCrown(),
 							3,
 						)
 						So(err, ShouldBeNil)
 
-						state, err = client.GetContractState(context.Background(), s.Algod, owner, appID)
+						state, err = client.GetContractState(s.Ctx, s.Algod, owner, appID)
 						So(err, ShouldBeNil)
 
 						So(state.InitPrice, ShouldEqual, 100000)
@@ -261,9 +307,9 @@ func TestBecomeKing(t *testing.T) {
 						comp := priceToBeKing - reward - adminFee
 
 						So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+(adminFee))
-						So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]-priceToBeKing-(transaction.MinTxnFee*5))
-						So(afterContractBalance.Amount, ShouldEqual, s.minBalance()+comp)
-						So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+beforeContractAccountInfo.Amount-s.minBalance()+reward)
+						So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]-priceToBeKing-crownFunding-(transaction.MinTxnFee*6))
+						So(afterContractBalance.Amount, ShouldEqual, s.minBalance()+crownFunding*state.Crowns+comp)
+						So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+beforeContractAccountInfo.Amount-s.minBalance()-crownFunding*(state.Crowns-1)+reward)
 					})
 				})
 			})
@@ -0,0 +1,191 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCrown(t *testing.T) {
+	Convey("Crown of the new king", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		appAddress := crypto.GetApplicationAddress(appID).String()
+
+		Convey("Mints the crown with the metadata and the king collects it", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			beforeBalances := s.getAccountsBalances()
+
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+			So(resp.Crown, ShouldNotEqual, 0)
+
+			newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(newState.Reign, ShouldEqual, 1)
+			So(newState.Crown, ShouldEqual, resp.Crown)
+			So(newState.Crowns, ShouldEqual, 1)
+
+			// The app creates the crown with the inner tx of the claim, the reserve is the king it was minted for.
+			So(resp.InnerTxns, ShouldHaveLength, 1)
+			So(resp.InnerTxns[0].AssetIndex, ShouldEqual, resp.Crown)
+			asset, err := s.Algod.GetAssetByID(resp.Crown).Do(s.Ctx)
+			So(err, ShouldBeNil)
+			So(asset.Params.Creator, ShouldEqual, appAddress)
+			So(asset.Params.Reserve, ShouldEqual, first.Address.String())
+
+			So(s.getAssetBalance(first.Address.String(), resp.Crown), ShouldEqual, 1)
+			So(s.getAssetBalance(appAddress, resp.Crown), ShouldEqual, 0)
+
+			metadata := struct {
+				Standard   string `json:"standard"`
+				Properties struct {
+					Message string `json:"message"`
+					Reign   uint64 `json:"reign"`
+					Price   uint64 `json:"price"`
+				} `json:"properties"`
+			}{}
+			err = json.Unmarshal(resp.InnerTxns[0].Transaction.Txn.Note, &metadata)
+			So(err, ShouldBeNil)
+			So(metadata.Standard, ShouldEqual, "arc69")
+			So(metadata.Properties.Message, ShouldEqual, "I am the king")
+			So(metadata.Properties.Reign, ShouldEqual, 1)
+			So(metadata.Properties.Price, ShouldEqual, state.ClaimPrice())
+
+			// The claimant pays for the min balance of the crown, then for the opt-in and the collect with its inner tx.
+			balances := s.getAccountsBalances()
+			So(balances[first.Address.String()], ShouldEqual,
+				beforeBalances[first.Address.String()]-state.ClaimPrice()-state.ClaimCrownFunding()-resp.Fees.Total-transaction.MinTxnFee*3)
+			So(s.getContractAccountInfo(appID).TotalCreatedAssets, ShouldEqual, 1)
+
+			Convey("Lists only the crowns the known apps minted for the account", func() {
+				// A look-alike minted by someone else, with the names of the crown and the king as reserve.
+				tx, err := transaction.MakeAssetCreateTxn(
+					second.Address.String(), nil, s.getSuggestedParams(), 1, 0, false, "", first.Address.String(), "", "", "CROWN", "King of Algo Crown", "", "",
+				)
+				So(err, ShouldBeNil)
+				fakeID := s.sendTx(second, tx).AssetIndex
+				s.optInAsset(first, fakeID)
+				s.transferAsset(second, first, fakeID, 1)
+
+				indexerClient, err := indexer.MakeClient("http://localhost:8980", "")
+				So(err, ShouldBeNil)
+
+				crowns, err := client.ListCrowns(s.Ctx, s.Algod, indexerClient, first.Address.String(), []uint64{appID})
+				So(err, ShouldBeNil)
+				So(crowns, ShouldHaveLength, 1)
+				So(crowns[0].AssetID, ShouldEqual, resp.Crown)
+				So(crowns[0].AppID, ShouldEqual, appID)
+
+				crowns, err = client.ListCrowns(s.Ctx, s.Algod, indexerClient, first.Address.String(), nil)
+				So(err, ShouldBeNil)
+				So(crowns, ShouldBeEmpty)
+			})
+
+			Convey("The next king gets its own crown", func() {
+				state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+				resp2, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+					3,
+				)
+				So(err, ShouldBeNil)
+				So(resp2.Crown, ShouldNotEqual, resp.Crown)
+				So(s.getAssetBalance(second.Address.String(), resp2.Crown), ShouldEqual, 1)
+				So(s.getAssetBalance(first.Address.String(), resp.Crown), ShouldEqual, 1)
+
+				newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+				So(newState.Reign, ShouldEqual, 2)
+				So(newState.Crowns, ShouldEqual, 2)
+			})
+
+			Convey("The app account keeps the min balance of the collected crown when it's decommissioned", func() {
+				_, err := client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldBeNil)
+
+				report, err := client.Decommission(s.Ctx, s.Algod, owner, appID, true, 3)
+				So(err, ShouldBeNil)
+				So(report.Crowns, ShouldEqual, 1)
+				So(report.Uncollected, ShouldBeEmpty)
+				So(report.Remainder, ShouldEqual, 0)
+				So(report.Locked, ShouldEqual, s.minBalance()+100000)
+				So(s.getContractAccountInfo(appID).Amount, ShouldEqual, report.Locked)
+			})
+		})
+
+		Convey("Leaves the crown in the app account", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king").LeaveCrown(),
+				3,
+			)
+			So(err, ShouldBeNil)
+			So(s.getAssetBalance(appAddress, resp.Crown), ShouldEqual, 1)
+
+			crownIDs, err := client.UncollectedCrowns(s.Ctx, s.Algod, appID, first.Address.String())
+			So(err, ShouldBeNil)
+			So(crownIDs, ShouldResemble, []uint64{resp.Crown})
+
+			Convey("Only the king it was minted for collects it", func() {
+				err := client.CollectCrown(s.Ctx, s.Algod, second, appID, resp.Crown, 3)
+				So(err, ShouldNotBeNil)
+
+				err = client.CollectCrown(s.Ctx, s.Algod, first, appID, resp.Crown, 3)
+				So(err, ShouldBeNil)
+				So(s.getAssetBalance(first.Address.String(), resp.Crown), ShouldEqual, 1)
+			})
+
+			Convey("The admin burns it once the delete is announced and closes the app account", func() {
+				_, err := client.BurnCrowns(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldNotBeNil)
+
+				_, err = client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldBeNil)
+
+				report, err := client.InspectDecommission(s.Ctx, s.Algod, appID)
+				So(err, ShouldBeNil)
+				So(report.Uncollected, ShouldResemble, []uint64{resp.Crown})
+				So(report.Locked, ShouldEqual, 0)
+
+				beforeBalances := s.getAccountsBalances()
+
+				_, err = client.Decommission(s.Ctx, s.Algod, owner, appID, true, 3)
+				So(err, ShouldBeNil)
+				So(s.getContractAccountInfo(appID).Amount, ShouldEqual, 0)
+
+				_, err = s.Algod.GetAssetByID(resp.Crown).Do(s.Ctx)
+				So(err, ShouldNotBeNil)
+
+				// The admin gets the min balances back, less the fees of the burn and the delete.
+				balances := s.getAccountsBalances()
+				So(balances[owner.Address.String()], ShouldEqual,
+					beforeBalances[owner.Address.String()]+report.Remainder-transaction.MinTxnFee*3-transaction.MinTxnFee*3)
+			})
+		})
+	})
+}
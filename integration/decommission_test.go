@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDecommission(t *testing.T) {
+	Convey("client.Decommission()", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		// Without an upgrade delay the delete is ready as soon as it's announced.
+		_, err = client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+		So(err, ShouldBeNil)
+
+		Convey("Deletes the app and returns the min balance to the admin when there is no king", func() {
+			beforeBalances := s.getAccountsBalances()
+
+			report, err := client.Decommission(s.Ctx, s.Algod, owner, appID, false, 3)
+			So(err, ShouldBeNil)
+			So(report.King, ShouldEqual, "")
+			So(report.Compensation, ShouldEqual, 0)
+			So(report.Remainder, ShouldEqual, s.minBalance())
+
+			_, err = s.Algod.GetApplicationByID(appID).Do(s.Ctx)
+			So(err, ShouldNotBeNil)
+
+			balances := s.getAccountsBalances()
+			So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+s.minBalance()-(transaction.MinTxnFee*2))
+		})
+
+		Convey("With a king", func() {
+			state, _ := client.GetContractState(s.Ctx, s.Algod, owner, appID)
+
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			Convey("Refuses to delete while the reign is active", func() {
+				report, err := client.Decommission(s.Ctx, s.Algod, owner, appID, false, 3)
+				So(errors.Is(err, client.ErrReignActive), ShouldBeTrue)
+				So(report.King, ShouldEqual, first.Address.String())
+
+				_, err = s.Algod.GetApplicationByID(appID).Do(s.Ctx)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Settles the king's compensation when forced", func() {
+				beforeBalances := s.getAccountsBalances()
+				beforeContractAccountInfo := s.getContractAccountInfo(appID)
+
+				report, err := client.Decommission(s.Ctx, s.Algod, owner, appID, true, 3)
+				So(err, ShouldBeNil)
+				So(report.Compensation, ShouldEqual, beforeContractAccountInfo.Amount-s.minBalance()-state.ClaimCrownFunding())
+
+				// The king collected the crown, so the app account keeps its min balance and the admin only pays the fees.
+				So(report.Locked, ShouldEqual, s.minBalance()+state.ClaimCrownFunding())
+				So(report.Remainder, ShouldEqual, 0)
+
+				balances := s.getAccountsBalances()
+				So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+report.Compensation)
+				So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]-(transaction.MinTxnFee*3))
+				So(s.getContractAccountInfo(appID).Amount, ShouldEqual, report.Locked)
+			})
+		})
+	})
+}
@@ -0,0 +1,202 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFactory(t *testing.T) {
+	Convey("Throne factory", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+
+		factoryID, err := client.DeployFactory(s.Ctx, s.Algod, owner, contracts.Version, 0)
+		So(err, ShouldBeNil)
+
+		appID, err := client.CreateThrone(s.Ctx, s.Algod, owner, factoryID, time.Hour, 0)
+		So(err, ShouldBeNil)
+
+		Convey("Creates thrones administered by the factory", func() {
+			state, err := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(err, ShouldBeNil)
+			So(state.Admin, ShouldEqual, crypto.GetApplicationAddress(factoryID).String())
+			So(state.ReignPeriod, ShouldEqual, 3600)
+			So(state.Validate(), ShouldBeNil)
+
+			So(s.getContractAccountInfo(appID).Amount, ShouldEqual, s.minBalance())
+		})
+
+		Convey("Lists its thrones", func() {
+			otherID, err := client.CreateThrone(s.Ctx, s.Algod, owner, factoryID, time.Hour*2, 0)
+			So(err, ShouldBeNil)
+
+			thrones, err := client.ListThrones(s.Ctx, s.Algod, factoryID, client.StatesOptions{})
+			So(err, ShouldBeNil)
+			So(thrones, ShouldHaveLength, 2)
+			So(thrones[0].AppID, ShouldEqual, appID)
+			So(thrones[1].AppID, ShouldEqual, otherID)
+			So(thrones[1].Err, ShouldBeNil)
+			So(thrones[1].State.ReignPeriod, ShouldEqual, 7200)
+		})
+
+		Convey("Passes the admin calls through to its thrones", func() {
+			err := client.PauseThrone(s.Ctx, s.Algod, owner, factoryID, appID)
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Paused, ShouldBeTrue)
+
+			err = client.UnpauseThrone(s.Ctx, s.Algod, owner, factoryID, appID)
+			So(err, ShouldBeNil)
+
+			// Without a king the params apply at once.
+			params := client.Params{AdminFee: 3, RewardMultiplier: 80, ReignPeriod: 2 * time.Hour}
+			err = client.SetThroneParams(s.Ctx, s.Algod, owner, factoryID, appID, params)
+			So(err, ShouldBeNil)
+
+			state, _ = client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Paused, ShouldBeFalse)
+			So(state.CurrentParams(), ShouldResemble, params)
+
+			// Only the factory is the admin of the throne.
+			err = client.Pause(s.Ctx, s.Algod, owner, appID, 3)
+			So(errors.Is(err, client.ErrNotAdmin), ShouldBeTrue)
+		})
+
+		Convey("Hands a throne over to another admin", func() {
+			err := client.HandOverThrone(s.Ctx, s.Algod, owner, factoryID, appID, first.Address.String())
+			So(err, ShouldBeNil)
+
+			thrones, err := client.ListThrones(s.Ctx, s.Algod, factoryID, client.StatesOptions{})
+			So(err, ShouldBeNil)
+			So(thrones, ShouldHaveLength, 0)
+
+			err = client.AcceptAdmin(s.Ctx, s.Algod, first, appID, 3)
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Admin, ShouldEqual, first.Address.String())
+
+			err = client.PauseThrone(s.Ctx, s.Algod, owner, factoryID, appID)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Timelocks its upgrades", func() {
+			approvalSource, clearSource, err := contracts.FactoryPrograms(contracts.Version)
+			So(err, ShouldBeNil)
+
+			approvalProgram, clearProgram, hash, err := client.CompileUpgrade(s.Ctx, s.Algod, approvalSource, clearSource)
+			So(err, ShouldBeNil)
+
+			err = client.ExecuteFactoryUpgrade(s.Ctx, s.Algod, owner, factoryID, approvalProgram, clearProgram)
+			So(errors.Is(err, client.ErrNoUpgrade), ShouldBeTrue)
+
+			lockedID, err := client.DeployFactory(s.Ctx, s.Algod, owner, contracts.Version, time.Hour)
+			So(err, ShouldBeNil)
+
+			upgrade, err := client.AnnounceFactoryUpgrade(s.Ctx, s.Algod, owner, lockedID, approvalProgram, clearProgram)
+			So(err, ShouldBeNil)
+			So(upgrade.Hash, ShouldResemble, hash)
+
+			err = client.ExecuteFactoryUpgrade(s.Ctx, s.Algod, owner, lockedID, approvalProgram, clearProgram)
+			So(errors.Is(err, client.ErrTimelocked), ShouldBeTrue)
+
+			err = client.CancelFactoryUpgrade(s.Ctx, s.Algod, owner, lockedID)
+			So(err, ShouldBeNil)
+
+			pending, err := client.GetFactoryUpgrade(s.Ctx, s.Algod, lockedID)
+			So(err, ShouldBeNil)
+			So(pending, ShouldBeNil)
+
+			// Without a delay the announced upgrade runs at once.
+			_, err = client.AnnounceFactoryUpgrade(s.Ctx, s.Algod, owner, factoryID, approvalProgram, clearProgram)
+			So(err, ShouldBeNil)
+
+			err = client.ExecuteFactoryUpgrade(s.Ctx, s.Algod, owner, factoryID, approvalProgram, clearProgram)
+			So(err, ShouldBeNil)
+
+			pending, err = client.GetFactoryUpgrade(s.Ctx, s.Algod, factoryID)
+			So(err, ShouldBeNil)
+			So(pending, ShouldBeNil)
+		})
+
+		Convey("Burns the crowns a throne still holds when it retires", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king").LeaveCrown(),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			_, err = client.AnnounceRetire(s.Ctx, s.Algod, owner, factoryID, appID)
+			So(err, ShouldBeNil)
+
+			report, err := client.RetireThrone(s.Ctx, s.Algod, owner, factoryID, appID, true)
+			So(err, ShouldBeNil)
+			So(report.Uncollected, ShouldBeEmpty)
+			So(report.Locked, ShouldEqual, 0)
+
+			_, err = s.Algod.GetAssetByID(resp.Crown).Do(s.Ctx)
+			So(err, ShouldNotBeNil)
+			So(s.getContractAccountInfo(appID).Amount, ShouldEqual, 0)
+		})
+
+		Convey("With a king", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			Convey("Refuses to retire while the reign is active", func() {
+				_, err := client.RetireThrone(s.Ctx, s.Algod, owner, factoryID, appID, false)
+				So(errors.Is(err, client.ErrReignActive), ShouldBeTrue)
+			})
+
+			Convey("Refuses to retire before the retire is announced", func() {
+				_, err := client.RetireThrone(s.Ctx, s.Algod, owner, factoryID, appID, true)
+				So(errors.Is(err, client.ErrNoUpgrade), ShouldBeTrue)
+			})
+
+			Convey("Retires the throne, pays the king and forwards the rest to the admin", func() {
+				upgrade, err := client.AnnounceRetire(s.Ctx, s.Algod, owner, factoryID, appID)
+				So(err, ShouldBeNil)
+				So(upgrade.Delete, ShouldBeTrue)
+
+				beforeBalances := s.getAccountsBalances()
+
+				report, err := client.RetireThrone(s.Ctx, s.Algod, owner, factoryID, appID, true)
+				So(err, ShouldBeNil)
+				So(report.King, ShouldEqual, first.Address.String())
+
+				_, err = s.Algod.GetApplicationByID(appID).Do(s.Ctx)
+				So(err, ShouldNotBeNil)
+
+				balances := s.getAccountsBalances()
+				So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]+report.Compensation)
+				So(balances[owner.Address.String()], ShouldBeGreaterThan, beforeBalances[owner.Address.String()])
+
+				thrones, err := client.ListThrones(s.Ctx, s.Algod, factoryID, client.StatesOptions{})
+				So(err, ShouldBeNil)
+				So(thrones, ShouldHaveLength, 0)
+			})
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFees(t *testing.T) {
+	Convey("Fee strategies of the claims", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+		Convey("Pays the min fee per tx by default", func() {
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+			So(resp.Fees.Mode, ShouldEqual, client.FeeMinimum)
+			// The app call also pays for the inner tx minting the crown.
+			So(resp.Fees.Txs, ShouldResemble, []uint64{transaction.MinTxnFee, transaction.MinTxnFee, transaction.MinTxnFee * 2})
+			So(resp.Fees.Total, ShouldEqual, state.ClaimFees(transaction.MinTxnFee))
+		})
+
+		Convey("Pools the fees of the group on the app call", func() {
+			beforeBalances := s.getAccountsBalances()
+
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king").
+					WithFees(client.FeeStrategy{Mode: client.FeePooled}).
+					LeaveCrown(),
+				3,
+			)
+			So(err, ShouldBeNil)
+			So(resp.Fees.Txs, ShouldResemble, []uint64{0, 0, transaction.MinTxnFee * 4})
+			So(resp.Fees.Total, ShouldEqual, transaction.MinTxnFee*4)
+
+			balances := s.getAccountsBalances()
+			So(balances[first.Address.String()], ShouldEqual, beforeBalances[first.Address.String()]-state.ClaimPrice()-state.ClaimCrownFunding()-resp.Fees.Total)
+		})
+
+		Convey("Covers the inner tx paying the dead king", func() {
+			period := 5 * time.Second
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", period, 0, "")
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			_, err = client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			state, _ = client.GetAppState(s.Ctx, s.Algod, appID)
+			if timeLeft := time.Until(state.EndOfReign); timeLeft > 0 {
+				time.Sleep(timeLeft + time.Second*5)
+			}
+
+			So(state.IsReignEnded(), ShouldBeTrue)
+			So(state.ClaimInnerTxs(), ShouldEqual, 2)
+			So(state.ClaimFees(transaction.MinTxnFee), ShouldEqual, transaction.MinTxnFee*6)
+
+			claimAfterTheReign := func(suggestedParams types.SuggestedParams, strategy client.FeeStrategy) client.Fees {
+				beforeBalances := s.getAccountsBalances()
+
+				resp, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(suggestedParams, appID, state, second, "I am the new king").WithFees(strategy).LeaveCrown(),
+					3,
+				)
+				So(err, ShouldBeNil)
+
+				newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+				So(newState.King, ShouldEqual, second.Address.String())
+
+				balances := s.getAccountsBalances()
+				So(balances[second.Address.String()], ShouldEqual, beforeBalances[second.Address.String()]-state.InitPrice-state.ClaimCrownFunding()-resp.Fees.Total)
+
+				return resp.Fees
+			}
+
+			Convey("Pools the fees above the min fee of the app call", func() {
+				fees := claimAfterTheReign(s.getSuggestedParams(), client.FeeStrategy{Mode: client.FeePooled})
+				So(fees.Txs, ShouldResemble, []uint64{0, 0, 0, transaction.MinTxnFee * 6})
+			})
+
+			Convey("Pays the congestion fees", func() {
+				// A congested network suggests a fee per byte, the app call pays more than the min fees.
+				suggestedParams := s.getSuggestedParams()
+				suggestedParams.FlatFee = false
+				suggestedParams.Fee = 10
+
+				fees := claimAfterTheReign(suggestedParams, client.FeeStrategy{Mode: client.FeeCongestion, MaxFee: transaction.MinTxnFee * 50})
+				So(fees.Txs[3], ShouldBeGreaterThan, transaction.MinTxnFee*3)
+				So(fees.Total, ShouldBeGreaterThan, state.ClaimFees(transaction.MinTxnFee))
+			})
+
+			Convey("Refuses to pay more than the max fee", func() {
+				_, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the king").
+						WithFees(client.FeeStrategy{Mode: client.FeeMinimum, MaxFee: transaction.MinTxnFee * 3}),
+					3,
+				)
+				So(errors.Is(err, client.ErrFeeCap), ShouldBeTrue)
+
+				newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+				So(newState.King, ShouldEqual, first.Address.String())
+			})
+		})
+	})
+}
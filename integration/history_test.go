@@ -0,0 +1,273 @@
+package integration
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeEventSource replays the deltas it holds like the indexer, counting the calls.
+type fakeEventSource struct {
+	deltas map[uint64][]client.StateDelta
+	calls  int
+}
+
+func (s *fakeEventSource) StateDeltas(_ context.Context, appID uint64, maxRound uint64) ([]client.StateDelta, error) {
+	s.calls++
+
+	deltas := []client.StateDelta{}
+	for _, delta := range s.deltas[appID] {
+		if maxRound == 0 || delta.Round <= maxRound {
+			deltas = append(deltas, delta)
+		}
+	}
+
+	return deltas, nil
+}
+
+func setUint(key string, value uint64) models.EvalDeltaKeyValue {
+	return models.EvalDeltaKeyValue{
+		Key:   base64.StdEncoding.EncodeToString([]byte(key)),
+		Value: models.EvalDelta{Action: 2, Uint: value},
+	}
+}
+
+func setBytes(key string, value []byte) models.EvalDeltaKeyValue {
+	return models.EvalDeltaKeyValue{
+		Key:   base64.StdEncoding.EncodeToString([]byte(key)),
+		Value: models.EvalDelta{Action: 1, Bytes: base64.StdEncoding.EncodeToString(value)},
+	}
+}
+
+func deleteKey(key string) models.EvalDeltaKeyValue {
+	return models.EvalDeltaKeyValue{
+		Key:   base64.StdEncoding.EncodeToString([]byte(key)),
+		Value: models.EvalDelta{Action: 3},
+	}
+}
+
+// The replay of the history doesn't need the sandbox.
+func TestHistory(t *testing.T) {
+	Convey("History of the state", t, func() {
+		admin := crypto.GenerateAccount()
+		king := crypto.GenerateAccount()
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		appID := uint64(1001)
+
+		creation := client.StateDelta{Round: 10, Time: start, TxID: "CREATE", Sender: admin.Address.String(), Delta: []models.EvalDeltaKeyValue{
+			setBytes("admin", admin.Address[:]),
+			setBytes("king", nil),
+			setUint("init_price", 100000),
+			setUint("king_price", 100000),
+			setUint("admin_fee", 5),
+			setUint("reward_multiplier", 75),
+			setUint("reign_period", 3600),
+			setUint("end_of_reign_timestamp", uint64(start.Add(time.Hour).Unix())),
+		}}
+		claim := client.StateDelta{Round: 20, Time: start.Add(time.Minute), TxID: "CLAIM", Sender: king.Address.String(), Delta: []models.EvalDeltaKeyValue{
+			setBytes("king", king.Address[:]),
+			setUint("king_price", 200000),
+			setUint("reign", 1),
+		}}
+		proposal := client.StateDelta{Round: 30, Time: start.Add(time.Hour), TxID: "PROPOSE", Sender: admin.Address.String(), Delta: []models.EvalDeltaKeyValue{
+			setBytes("pending_admin", king.Address[:]),
+		}}
+		cancel := client.StateDelta{Round: 30, Time: start.Add(time.Hour), TxID: "CANCEL", Sender: admin.Address.String(), Delta: []models.EvalDeltaKeyValue{
+			deleteKey("pending_admin"),
+		}}
+
+		source := &fakeEventSource{deltas: map[uint64][]client.StateDelta{
+			appID: {creation, claim, proposal, cancel},
+			// Another app on the same source.
+			2002: {{Round: 15, TxID: "OTHER", Delta: []models.EvalDeltaKeyValue{setUint("king_price", 1)}}},
+		}}
+
+		Convey("Replays every change in order", func() {
+			changes, err := client.StateHistory(context.Background(), source, appID, 0)
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 4)
+
+			So(changes[0].TxID, ShouldEqual, "CREATE")
+			So(changes[0].State.Admin, ShouldEqual, admin.Address.String())
+			So(changes[0].State.King, ShouldEqual, "")
+			So(changes[0].State.KingPrice, ShouldEqual, 100000)
+
+			So(changes[1].State.King, ShouldEqual, king.Address.String())
+			So(changes[1].State.KingPrice, ShouldEqual, 200000)
+			So(changes[1].State.Reign, ShouldEqual, 1)
+			// The keys the change didn't touch keep their value.
+			So(changes[1].State.AdminFee, ShouldEqual, 5)
+
+			So(changes[2].State.PendingAdmin, ShouldEqual, king.Address.String())
+			So(changes[3].State.PendingAdmin, ShouldEqual, "")
+			So(changes[3].State.Raw, ShouldNotContainKey, "pending_admin")
+		})
+
+		Convey("Rebuilds the state at a round", func() {
+			state, err := client.StateAt(context.Background(), source, appID, 25)
+			So(err, ShouldBeNil)
+			So(state.King, ShouldEqual, king.Address.String())
+			So(state.PendingAdmin, ShouldEqual, "")
+
+			state, err = client.StateAt(context.Background(), source, appID, 10)
+			So(err, ShouldBeNil)
+			So(state.King, ShouldEqual, "")
+
+			_, err = client.StateAt(context.Background(), source, appID, 9)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Returns the changes of a time range", func() {
+			changes, err := client.StateChangesBetween(context.Background(), source, appID, start.Add(time.Second), start.Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 3)
+			So(changes[0].TxID, ShouldEqual, "CLAIM")
+			So(changes[2].TxID, ShouldEqual, "CANCEL")
+		})
+
+		Convey("Fails on an unknown delta action", func() {
+			source.deltas[appID] = append(source.deltas[appID], client.StateDelta{Round: 40, TxID: "BROKEN", Delta: []models.EvalDeltaKeyValue{
+				{Key: base64.StdEncoding.EncodeToString([]byte("king_price")), Value: models.EvalDelta{Action: 9}},
+			}})
+
+			_, err := client.StateHistory(context.Background(), source, appID, 0)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "tx BROKEN")
+		})
+
+		Convey("Syncs the deltas to a local event store", func() {
+			store := client.NewFileEventStore(filepath.Join(t.TempDir(), "events.jsonl"))
+
+			err := store.Sync(context.Background(), source, appID)
+			So(err, ShouldBeNil)
+
+			stored, err := store.StateDeltas(context.Background(), appID, 0)
+			So(err, ShouldBeNil)
+			So(stored, ShouldHaveLength, 4)
+			So(stored[0].TxID, ShouldEqual, "CREATE")
+			So(stored[3].TxID, ShouldEqual, "CANCEL")
+
+			Convey("And only appends the new ones on the next sync", func() {
+				source.deltas[appID] = append(source.deltas[appID], client.StateDelta{Round: 40, TxID: "ABDICATE", Delta: []models.EvalDeltaKeyValue{
+					setBytes("king", nil),
+				}})
+
+				err := store.Sync(context.Background(), source, appID)
+				So(err, ShouldBeNil)
+
+				err = store.Sync(context.Background(), source, appID)
+				So(err, ShouldBeNil)
+
+				stored, err := store.StateDeltas(context.Background(), appID, 0)
+				So(err, ShouldBeNil)
+				So(stored, ShouldHaveLength, 5)
+
+				// The store replays like the source.
+				state, err := client.StateAt(context.Background(), store, appID, 40)
+				So(err, ShouldBeNil)
+				So(state.King, ShouldEqual, "")
+
+				state, err = client.StateAt(context.Background(), store, appID, 35)
+				So(err, ShouldBeNil)
+				So(state.King, ShouldEqual, king.Address.String())
+			})
+
+			Convey("And keeps the deltas of the apps apart", func() {
+				err := store.Sync(context.Background(), source, 2002)
+				So(err, ShouldBeNil)
+
+				stored, err := store.StateDeltas(context.Background(), 2002, 0)
+				So(err, ShouldBeNil)
+				So(stored, ShouldHaveLength, 1)
+				So(stored[0].TxID, ShouldEqual, "OTHER")
+
+				stored, err = store.StateDeltas(context.Background(), appID, 20)
+				So(err, ShouldBeNil)
+				So(stored, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("Reads the deltas of an app created by the factory from the indexer", func() {
+			factoryID := uint64(500)
+			factoryAddress := crypto.GetApplicationAddress(factoryID).String()
+
+			// The factory creates the throne with an inner tx, the indexer returns the call of the factory.
+			factoryCall := models.Transaction{
+				Id:                     "FACTORY",
+				Type:                   "appl",
+				Sender:                 admin.Address.String(),
+				ConfirmedRound:         10,
+				RoundTime:              uint64(start.Unix()),
+				ApplicationTransaction: models.TransactionApplication{ApplicationId: factoryID},
+				GlobalStateDelta:       []models.EvalDeltaKeyValue{setUint("thrones", 1)},
+				InnerTxns: []models.Transaction{
+					{
+						Type:                    "appl",
+						Sender:                  factoryAddress,
+						CreatedApplicationIndex: appID,
+						GlobalStateDelta:        creation.Delta,
+					},
+					{
+						Type:   "pay",
+						Sender: factoryAddress,
+					},
+				},
+			}
+			directCall := models.Transaction{
+				Id:                     "CLAIM",
+				Type:                   "appl",
+				Sender:                 king.Address.String(),
+				ConfirmedRound:         20,
+				RoundTime:              uint64(start.Add(time.Minute).Unix()),
+				ApplicationTransaction: models.TransactionApplication{ApplicationId: appID},
+				GlobalStateDelta:       claim.Delta,
+			}
+
+			requests := make(chan *http.Request, 2)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests <- r
+
+				json.NewEncoder(w).Encode(models.TransactionsResponse{
+					CurrentRound: 30,
+					Transactions: []models.Transaction{factoryCall, directCall},
+				})
+			}))
+			defer server.Close()
+
+			indexerClient, err := indexer.MakeClient(server.URL, "")
+			So(err, ShouldBeNil)
+
+			deltas, err := client.IndexerSource{Indexer: indexerClient}.StateDeltas(context.Background(), appID, 0)
+			So(err, ShouldBeNil)
+			So(deltas, ShouldHaveLength, 2)
+
+			request := <-requests
+			So(request.URL.Path, ShouldEqual, "/v2/transactions")
+			So(request.URL.Query().Get("application-id"), ShouldEqual, "1001")
+
+			So(deltas[0].TxID, ShouldEqual, "FACTORY/inner/0")
+			So(deltas[0].Sender, ShouldEqual, factoryAddress)
+			So(deltas[0].Round, ShouldEqual, 10)
+			So(deltas[0].Time, ShouldEqual, start)
+			So(deltas[0].Delta, ShouldResemble, creation.Delta)
+
+			So(deltas[1].TxID, ShouldEqual, "CLAIM")
+
+			state, err := client.StateAt(context.Background(), client.IndexerSource{Indexer: indexerClient}, appID, 20)
+			So(err, ShouldBeNil)
+			So(state.Admin, ShouldEqual, admin.Address.String())
+			So(state.King, ShouldEqual, king.Address.String())
+		})
+	})
+}
@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJournal(t *testing.T) {
+	Convey("Journal of the claims and deploys", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+
+		journal := client.NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+		ctx := client.WithJournal(s.Ctx, journal)
+
+		appID, err := client.Deploy(ctx, s.Algod, owner, nil, "throne", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+		Convey("Records the outcome of the deploy and the claim", func() {
+			resp, err := client.BecomeKing(
+				ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			entries, err := journal.Entries()
+			So(err, ShouldBeNil)
+			So(entries, ShouldHaveLength, 2)
+
+			So(entries[0].Kind, ShouldEqual, client.JournalDeploy)
+			So(entries[0].Status, ShouldEqual, client.JournalConfirmed)
+			So(entries[0].AppID, ShouldEqual, appID)
+			So(entries[0].Sender, ShouldEqual, owner.Address.String())
+			So(entries[0].Done, ShouldBeTrue)
+
+			So(entries[1].Kind, ShouldEqual, client.JournalClaim)
+			So(entries[1].Status, ShouldEqual, client.JournalConfirmed)
+			So(entries[1].Round, ShouldEqual, resp.ConfirmedRound)
+			So(entries[1].Reign, ShouldEqual, state.Reign+1)
+			So(entries[1].TxIDs, ShouldHaveLength, 3)
+
+			pending, err := journal.Pending()
+			So(err, ShouldBeNil)
+			So(pending, ShouldBeEmpty)
+		})
+
+		Convey("Reconciles a claim sent before a crash", func() {
+			suggestedParams := s.getSuggestedParams()
+			signedBytes, signedGroup, err := client.MakeBecomeKingTx(
+				s.Ctx,
+				client.NewBecomeKingParams(suggestedParams, appID, state, first, "I am the king"),
+			)
+			So(err, ShouldBeNil)
+
+			txIDs := []string{}
+			for _, stx := range signedGroup {
+				txIDs = append(txIDs, crypto.GetTxID(stx.Txn))
+			}
+
+			last := signedGroup[len(signedGroup)-1].Txn
+			err = journal.Append(client.JournalEntry{
+				Kind:       client.JournalClaim,
+				GroupID:    txIDs[0],
+				TxIDs:      txIDs,
+				Sender:     first.Address.String(),
+				AppID:      appID,
+				FirstValid: uint64(last.FirstValid),
+				LastValid:  uint64(last.LastValid),
+				Status:     client.JournalPending,
+			})
+			So(err, ShouldBeNil)
+
+			_, err = s.Algod.SendRawTransaction(signedBytes).Do(s.Ctx)
+			So(err, ShouldBeNil)
+
+			reconciled, err := journal.Reconcile(s.Ctx, s.Algod, client.DefaultSubmitOptions)
+			So(err, ShouldBeNil)
+			So(reconciled, ShouldHaveLength, 1)
+			So(reconciled[0].Status, ShouldEqual, client.JournalConfirmed)
+			So(reconciled[0].Round, ShouldBeGreaterThan, 0)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.King, ShouldEqual, first.Address.String())
+		})
+
+		Convey("Reconciles a claim never sent as expired", func() {
+			status, err := s.Algod.Status().Do(s.Ctx)
+			So(err, ShouldBeNil)
+
+			err = journal.Append(client.JournalEntry{
+				Kind:       client.JournalClaim,
+				GroupID:    "lost",
+				TxIDs:      []string{"LOSTTXID"},
+				Sender:     first.Address.String(),
+				AppID:      appID,
+				FirstValid: status.LastRound - 1,
+				LastValid:  status.LastRound - 1,
+				Status:     client.JournalPending,
+			})
+			So(err, ShouldBeNil)
+
+			reconciled, err := journal.Reconcile(s.Ctx, s.Algod, client.DefaultSubmitOptions)
+			So(err, ShouldBeNil)
+			So(reconciled, ShouldHaveLength, 1)
+			So(reconciled[0].Status, ShouldEqual, client.JournalExpired)
+		})
+
+		Convey("Reconciles the groups after one it can't settle", func() {
+			status, err := s.Algod.Status().Do(s.Ctx)
+			So(err, ShouldBeNil)
+
+			// The node refuses to look up a malformed tx ID, so the group stays pending.
+			err = journal.Append(client.JournalEntry{
+				Kind:       client.JournalClaim,
+				GroupID:    "unknown",
+				TxIDs:      []string{"not-a-tx-id"},
+				Sender:     first.Address.String(),
+				AppID:      appID,
+				FirstValid: status.LastRound - 1,
+				LastValid:  status.LastRound - 1,
+				Status:     client.JournalPending,
+			})
+			So(err, ShouldBeNil)
+
+			err = journal.Append(client.JournalEntry{
+				Kind:       client.JournalClaim,
+				GroupID:    "lost",
+				TxIDs:      []string{"LOSTTXID"},
+				Sender:     first.Address.String(),
+				AppID:      appID,
+				FirstValid: status.LastRound - 1,
+				LastValid:  status.LastRound - 1,
+				Status:     client.JournalPending,
+			})
+			So(err, ShouldBeNil)
+
+			reconciled, err := journal.Reconcile(s.Ctx, s.Algod, client.DefaultSubmitOptions)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "group unknown")
+			So(reconciled, ShouldHaveLength, 1)
+			So(reconciled[0].GroupID, ShouldEqual, "lost")
+			So(reconciled[0].Status, ShouldEqual, client.JournalExpired)
+
+			pending, err := journal.Pending()
+			So(err, ShouldBeNil)
+			So(pending, ShouldHaveLength, 1)
+			So(pending[0].GroupID, ShouldEqual, "unknown")
+		})
+
+		Convey("Completes a deploy confirmed before a crash", func() {
+			entries, err := journal.Entries()
+			So(err, ShouldBeNil)
+
+			// The process stopped after the creation, before the registry.
+			deploy := entries[0]
+			deploy.Done = false
+			err = journal.Append(deploy)
+			So(err, ShouldBeNil)
+
+			incomplete, err := journal.Incomplete()
+			So(err, ShouldBeNil)
+			So(incomplete, ShouldHaveLength, 1)
+			So(incomplete[0].AppID, ShouldEqual, appID)
+			So(incomplete[0].Deploy.InitBalance, ShouldEqual, client.MinBalance)
+
+			registry, err := client.LoadRegistry(filepath.Join(t.TempDir(), "apps.json"))
+			So(err, ShouldBeNil)
+
+			err = client.CompleteDeploy(ctx, s.Algod, first, registry, incomplete[0])
+			So(err, ShouldNotBeNil)
+
+			err = client.CompleteDeploy(ctx, s.Algod, owner, registry, incomplete[0])
+			So(err, ShouldBeNil)
+
+			entry, err := registry.Lookup("", "throne")
+			So(err, ShouldBeNil)
+			So(entry.AppID, ShouldEqual, appID)
+
+			incomplete, err = journal.Incomplete()
+			So(err, ShouldBeNil)
+			So(incomplete, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package integration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// The keystore doesn't need the sandbox.
+func TestKeystore(t *testing.T) {
+	Convey("Keystore", t, func() {
+		path := filepath.Join(t.TempDir(), "keystore.json")
+		passphrase := []byte("correct horse battery staple")
+
+		alice := crypto.GenerateAccount()
+		bob := crypto.GenerateAccount()
+
+		keystore, err := client.OpenKeystore(path)
+		So(err, ShouldBeNil)
+		So(keystore.List(), ShouldBeEmpty)
+
+		So(keystore.Import("alice", alice.PrivateKey, passphrase), ShouldBeNil)
+		So(keystore.Import("bob", bob.PrivateKey, passphrase), ShouldBeNil)
+
+		Convey("Reads back the imported accounts from the file", func() {
+			keystore, err := client.OpenKeystore(path)
+			So(err, ShouldBeNil)
+			So(keystore.List(), ShouldResemble, []client.KeystoreEntry{
+				{Name: "alice", Address: alice.Address.String()},
+				{Name: "bob", Address: bob.Address.String()},
+			})
+
+			account, err := keystore.Account("alice", passphrase)
+			So(err, ShouldBeNil)
+			So(account.Address, ShouldEqual, alice.Address)
+			So(account.PrivateKey, ShouldResemble, alice.PrivateKey)
+		})
+
+		Convey("Exports the mnemonic it imports", func() {
+			words, err := keystore.Export("alice", passphrase)
+			So(err, ShouldBeNil)
+
+			expected, err := mnemonic.FromPrivateKey(alice.PrivateKey)
+			So(err, ShouldBeNil)
+			So(words, ShouldEqual, expected)
+
+			So(keystore.ImportMnemonic("alice-again", words+"\n", passphrase), ShouldBeNil)
+
+			account, err := keystore.Account("alice-again", passphrase)
+			So(err, ShouldBeNil)
+			So(account.Address, ShouldEqual, alice.Address)
+		})
+
+		Convey("Refuses to import a name twice", func() {
+			So(keystore.Import("alice", bob.PrivateKey, passphrase), ShouldNotBeNil)
+		})
+
+		Convey("Refuses a wrong passphrase", func() {
+			_, err := keystore.Account("alice", []byte("wrong"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "wrong passphrase")
+
+			_, err = keystore.Export("alice", []byte("wrong"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Refuses an unknown account", func() {
+			_, err := keystore.Account("carol", passphrase)
+			So(errors.Is(err, client.ErrAccountNotFound), ShouldBeTrue)
+		})
+
+		Convey("Refuses a tampered ciphertext", func() {
+			acc := keystore.Accounts["alice"]
+			acc.Ciphertext[0] ^= 1
+			keystore.Accounts["alice"] = acc
+
+			_, err := keystore.Account("alice", passphrase)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Refuses an entry moved to another name", func() {
+			keystore.Accounts["alice"], keystore.Accounts["bob"] = keystore.Accounts["bob"], keystore.Accounts["alice"]
+
+			_, err := keystore.Account("alice", passphrase)
+			So(err, ShouldNotBeNil)
+
+			_, err = keystore.Account("bob", passphrase)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Refuses an entry with another address", func() {
+			acc := keystore.Accounts["alice"]
+			acc.Address = bob.Address.String()
+			keystore.Accounts["alice"] = acc
+
+			_, err := keystore.Account("alice", passphrase)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Writes the file for the owner only", func() {
+			info, err := os.Stat(path)
+			So(err, ShouldBeNil)
+			So(info.Mode().Perm(), ShouldEqual, os.FileMode(0600))
+
+			// No temporary file is left behind.
+			files, err := os.ReadDir(filepath.Dir(path))
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 1)
+		})
+
+		Convey("Refuses an unsupported version", func() {
+			So(os.WriteFile(path, []byte(`{"version": 2, "accounts": {}}`), 0600), ShouldBeNil)
+
+			_, err := client.OpenKeystore(path)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestPassphrase(t *testing.T) {
+	Convey("Passphrase sources", t, func() {
+		Convey("Reads an environment variable", func() {
+			t.Setenv("KOA_TEST_PASSPHRASE", "from env")
+
+			source, err := client.ParsePassphrase("env:KOA_TEST_PASSPHRASE")
+			So(err, ShouldBeNil)
+
+			passphrase, err := source()
+			So(err, ShouldBeNil)
+			So(string(passphrase), ShouldEqual, "from env")
+		})
+
+		Convey("Fails on an unset environment variable", func() {
+			source, err := client.ParsePassphrase("env:KOA_TEST_PASSPHRASE_UNSET")
+			So(err, ShouldBeNil)
+
+			_, err = source()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Reads the first line of a file", func() {
+			path := filepath.Join(t.TempDir(), "passphrase")
+			So(os.WriteFile(path, []byte("from file\r\nignored\n"), 0600), ShouldBeNil)
+
+			source, err := client.ParsePassphrase("file:" + path)
+			So(err, ShouldBeNil)
+
+			passphrase, err := source()
+			So(err, ShouldBeNil)
+			So(string(passphrase), ShouldEqual, "from file")
+		})
+
+		Convey("Fails on a missing file", func() {
+			source, err := client.ParsePassphrase("file:" + filepath.Join(t.TempDir(), "missing"))
+			So(err, ShouldBeNil)
+
+			_, err = source()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Refuses an unknown source", func() {
+			_, err := client.ParsePassphrase("vault:secret")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,24 @@
+package integration
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// testWriter writes the logs through the test, so they show with its output.
+type testWriter struct {
+	t *testing.T
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+
+	return len(p), nil
+}
+
+// newTestLogger logs everything the client does through the test, go test shows it for the failed tests or with -v.
+func newTestLogger(t *testing.T) *slog.Logger {
+	return slog.New(slog.NewTextHandler(testWriter{t: t}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
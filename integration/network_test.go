@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNetwork(t *testing.T) {
+	Convey("Network of the node", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+
+		cfg, err := newConfig()
+		So(err, ShouldBeNil)
+
+		profile := client.Profile{}
+		profile.Algod.Endpoint = cfg.AlgodEndpoint
+		profile.Algod.APIToken = cfg.AlgodToken
+
+		version, err := s.Algod.Versions().Do(s.Ctx)
+		So(err, ShouldBeNil)
+
+		Convey("Connects to a node of the network of the profile", func() {
+			profile.GenesisID = version.GenesisID
+
+			_, err := client.NewAlgodClient(s.Ctx, profile)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Refuses a node of another network", func() {
+			profile.GenesisID = "testnet-v1.0"
+
+			_, err := client.NewAlgodClient(s.Ctx, profile)
+			So(errors.Is(err, client.ErrWrongNetwork), ShouldBeTrue)
+		})
+
+		Convey("Refuses to deploy with the profile of another network", func() {
+			profile.GenesisID = "testnet-v1.0"
+
+			_, err := client.Deploy(client.WithProfile(s.Ctx, profile), s.Algod, owner, nil, "throne", time.Hour, 0, "")
+			So(errors.Is(err, client.ErrWrongNetwork), ShouldBeTrue)
+		})
+
+		Convey("Refuses to sign without a profile", func() {
+			_, err := client.Deploy(client.WithLogger(context.Background(), s.Logger), s.Algod, owner, nil, "", time.Hour, 0, "")
+			So(errors.Is(err, client.ErrNoProfile), ShouldBeTrue)
+		})
+
+		Convey("Refuses to claim with the params of another network", func() {
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "throne", time.Hour, 0, "")
+			So(err, ShouldBeNil)
+
+			state, err := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(err, ShouldBeNil)
+
+			suggestedParams := s.getSuggestedParams()
+			suggestedParams.GenesisID = "testnet-v1.0"
+
+			_, err = client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(suggestedParams, appID, state, first, "I am the king"),
+				3,
+			)
+			So(errors.Is(err, client.ErrWrongNetwork), ShouldBeTrue)
+		})
+	})
+}
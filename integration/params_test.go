@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParams(t *testing.T) {
+	Convey("Params of the next reign", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+		params := client.Params{AdminFee: 3, RewardMultiplier: 80, ReignPeriod: 2 * time.Hour}
+
+		Convey("Shows the changes against the state", func() {
+			_, changes, err := client.PlanParams(s.Ctx, s.Algod, appID, params)
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 3)
+			So(changes[0].Name, ShouldEqual, "admin fee")
+			So(changes[0].Current, ShouldEqual, "5%")
+			So(changes[0].Proposed, ShouldEqual, "3%")
+		})
+
+		Convey("Refuses params out of the bounds", func() {
+			err := client.SetParams(s.Ctx, s.Algod, owner, appID, client.Params{AdminFee: 11, RewardMultiplier: 75, ReignPeriod: time.Hour}, 3)
+			So(err, ShouldNotBeNil)
+
+			err = client.SetParams(s.Ctx, s.Algod, owner, appID, client.Params{AdminFee: 5, RewardMultiplier: 75, ReignPeriod: time.Minute}, 3)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Applies right away when there is no king", func() {
+			err := client.SetParams(s.Ctx, s.Algod, owner, appID, params, 3)
+			So(err, ShouldBeNil)
+
+			newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(newState.CurrentParams(), ShouldResemble, params)
+			So(newState.PendingParams, ShouldBeNil)
+		})
+
+		Convey("With a king", func() {
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			err = client.SetParams(s.Ctx, s.Algod, owner, appID, params, 3)
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.AdminFee, ShouldEqual, 5)
+			So(state.PendingParams, ShouldNotBeNil)
+			So(*state.PendingParams, ShouldResemble, params)
+
+			Convey("The next claim of the reign pays under the current params", func() {
+				beforeBalances := s.getAccountsBalances()
+
+				_, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+					3,
+				)
+				So(err, ShouldBeNil)
+
+				balances := s.getAccountsBalances()
+				So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+multiplyPercentage(state.KingPrice, 5))
+			})
+
+			Convey("The params apply once the king abdicates", func() {
+				_, err := client.Abdicate(s.Ctx, s.Algod, first, appID, 3)
+				So(err, ShouldBeNil)
+
+				newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+				So(newState.CurrentParams(), ShouldResemble, params)
+				So(newState.PendingParams, ShouldBeNil)
+				So(newState.EndOfReign, ShouldHappenAfter, time.Now().Add(time.Hour))
+			})
+		})
+
+		Convey("The claim after the reign pays under the pending params", func() {
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", 5*time.Second, 0, "")
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			_, err = client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			err = client.SetParams(s.Ctx, s.Algod, owner, appID, params, 3)
+			So(err, ShouldBeNil)
+
+			state, _ = client.GetAppState(s.Ctx, s.Algod, appID)
+			if timeLeft := time.Until(state.EndOfReign); timeLeft > 0 {
+				time.Sleep(timeLeft + time.Second*5)
+			}
+
+			So(state.ClaimParams(), ShouldResemble, params)
+
+			beforeBalances := s.getAccountsBalances()
+
+			_, err = client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+
+			balances := s.getAccountsBalances()
+			So(balances[owner.Address.String()], ShouldEqual, beforeBalances[owner.Address.String()]+multiplyPercentage(state.InitPrice, params.AdminFee))
+
+			newState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(newState.King, ShouldEqual, second.Address.String())
+			So(newState.CurrentParams(), ShouldResemble, params)
+			So(newState.PendingParams, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPause(t *testing.T) {
+	Convey("Pause of the claims", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+		So(state.Paused, ShouldBeFalse)
+
+		_, err = client.BecomeKing(
+			s.Ctx,
+			s.Algod,
+			false,
+			client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+			3,
+		)
+		So(err, ShouldBeNil)
+
+		unpausedState, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+		err = client.Pause(s.Ctx, s.Algod, owner, appID, 3)
+		So(err, ShouldBeNil)
+
+		Convey("Refuses to build a claim", func() {
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Paused, ShouldBeTrue)
+
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+				3,
+			)
+			So(errors.Is(err, client.ErrPaused), ShouldBeTrue)
+		})
+
+		Convey("The contract rejects a claim built before the pause", func() {
+			_, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, unpausedState, second, "I am the new king"),
+				3,
+			)
+			So(err, ShouldNotBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.King, ShouldEqual, first.Address.String())
+		})
+
+		Convey("The king can still collect the compensation", func() {
+			compensation, err := client.Abdicate(s.Ctx, s.Algod, first, appID, 3)
+			So(err, ShouldBeNil)
+			So(compensation, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Only the admin can pause", func() {
+			err := client.Unpause(s.Ctx, s.Algod, first, appID, 3)
+			So(errors.Is(err, client.ErrNotAdmin), ShouldBeTrue)
+		})
+
+		Convey("Accepts claims again once unpaused", func() {
+			err := client.Unpause(s.Ctx, s.Algod, owner, appID, 3)
+			So(err, ShouldBeNil)
+
+			state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(state.Paused, ShouldBeFalse)
+
+			_, err = client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the new king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFormatStateStrict(t *testing.T) {
+	Convey("client.FormatStateStrict()", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		rawState, err := client.ReadGlobalState(s.Ctx, s.Algod, owner.Address.String(), appID)
+		So(err, ShouldBeNil)
+
+		Convey("Decodes the state of a new app", func() {
+			state, err := client.FormatStateStrict(rawState)
+			So(err, ShouldBeNil)
+			So(state.Validate(), ShouldBeNil)
+			So(state.Raw, ShouldContainKey, "king_price")
+		})
+
+		Convey("Fails when a key is missing", func() {
+			_, err := client.FormatStateStrict(rawState[1:])
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Fails when a key of the variant is missing", func() {
+			withoutReign := []models.TealKeyValue{}
+			for _, keyValue := range rawState {
+				if keyValue.Key != base64.StdEncoding.EncodeToString([]byte("reign")) {
+					withoutReign = append(withoutReign, keyValue)
+				}
+			}
+
+			_, err := client.FormatStateStrict(withoutReign)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "missing global state keys: reign")
+		})
+	})
+}
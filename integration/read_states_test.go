@@ -0,0 +1,39 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetContractStates(t *testing.T) {
+	Convey("client.GetContractStates()", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+
+		appID1, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		appID2, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour*2, 0, "")
+		So(err, ShouldBeNil)
+
+		Convey("Reads the state of many apps concurrently, in the order of the app IDs", func() {
+			opts := client.StatesOptions{Concurrency: 2, RequestsPerSecond: 10}
+			states := client.GetContractStates(s.Ctx, s.Algod, []uint64{appID1, 0xFFFFFFFF, appID2}, opts)
+			So(states, ShouldHaveLength, 3)
+
+			So(states[0].AppID, ShouldEqual, appID1)
+			So(states[0].Err, ShouldBeNil)
+			So(states[0].State.ReignPeriod, ShouldEqual, 3600)
+
+			So(states[1].Err, ShouldNotBeNil)
+
+			So(states[2].AppID, ShouldEqual, appID2)
+			So(states[2].Err, ShouldBeNil)
+			So(states[2].State.ReignPeriod, ShouldEqual, 7200)
+		})
+	})
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSubmit(t *testing.T) {
+	Convey("Submission of the claims", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+		second := s.Accounts[2]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+		Convey("Confirms the claim within the rounds waited", func() {
+			suggestedParams := s.getSuggestedParams()
+
+			resp, err := client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(suggestedParams, appID, state, first, "I am the king"),
+				3,
+			)
+			So(err, ShouldBeNil)
+			So(resp.ConfirmedRound, ShouldBeLessThanOrEqualTo, uint64(suggestedParams.FirstRoundValid)+3)
+			So(resp.Transaction.Txn.Lease, ShouldNotResemble, [32]byte{})
+		})
+
+		Convey("Refuses a second claim of the account for the same reign", func() {
+			signedBytes, _, err := client.MakeBecomeKingTx(
+				s.Ctx,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king"),
+			)
+			So(err, ShouldBeNil)
+
+			_, err = s.Algod.SendRawTransaction(signedBytes).Do(s.Ctx)
+			So(err, ShouldBeNil)
+
+			_, err = client.BecomeKing(
+				s.Ctx,
+				s.Algod,
+				false,
+				client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, first, "I am the king again"),
+				3,
+			)
+			So(errors.Is(err, client.ErrClaimPending), ShouldBeTrue)
+
+			Convey("But not a claim of another account", func() {
+				state, _ := client.GetAppState(s.Ctx, s.Algod, appID)
+
+				_, err := client.BecomeKing(
+					s.Ctx,
+					s.Algod,
+					false,
+					client.NewBecomeKingParams(s.getSuggestedParams(), appID, state, second, "I am the king"),
+					3,
+				)
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
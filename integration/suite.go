@@ -1,53 +1,72 @@
 package integration
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
-	"io/fs"
+	"log/slog"
 	"math"
 	"os"
 	"strings"
 	"sync"
+	"testing"
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/common"
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
-	"github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/pkg/errors"
+	"github.com/qrksp/king-of-algo/client"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/sync/errgroup"
 )
 
 type suite struct {
 	Algod    *algod.Client
-	Accounts []crypto.Account
+	Accounts []client.Account
+	// Ctx logs the client operations through the test.
+	Ctx    context.Context
+	Logger *slog.Logger
 }
 
-func NewSuite() *suite {
+func NewSuite(t *testing.T) *suite {
+	logger := newTestLogger(t)
+
+	// The spans of the client operations are written through the test too.
+	tracerProvider, err := client.NewStdoutTracerProvider(testWriter{t: t})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	otel.SetTracerProvider(tracerProvider)
+	t.Cleanup(func() {
+		tracerProvider.Shutdown(context.Background())
+	})
+
 	cfg, err := newConfig()
 	if err != nil {
-		panic(err)
+		t.Fatalf("%+v", err)
 	}
 
-	algodClient, err := algod.MakeClientWithHeaders(cfg.AlgodEndpoint, cfg.AlgodToken, []*common.Header{
+	algodClient, err := algod.MakeClientWithTransport(cfg.AlgodEndpoint, cfg.AlgodToken, []*common.Header{
 		{
 			Key:   "x-api-key",
 			Value: cfg.AlgodToken,
 		},
-	})
+	}, client.NewLoggingTransport(logger, nil))
 	if err != nil {
-		panic(err)
+		t.Fatalf("%+v", err)
 	}
 
 	status, err := algodClient.Status().Do(context.Background())
 	if err != nil {
-		panic(err)
+		t.Fatalf("%+v", err)
 	}
 
 	if status.LastRound == 0 {
-		fmt.Println("SANDBOX OFF")
+		logger.Warn("sandbox off", "endpoint", cfg.AlgodEndpoint)
 		// err := sandbox.start()
 		// if err != nil {
 		// 	panic(err)
@@ -57,7 +76,7 @@ func NewSuite() *suite {
 	// I don't want to commit my home dir.
 	dirname, err := os.UserHomeDir()
 	if err != nil {
-		panic(err)
+		t.Fatalf("%+v", err)
 	}
 
 	sandboxPath := strings.Replace(cfg.SandboxRepoPath, "$HOME", dirname, -1)
@@ -68,40 +87,70 @@ func NewSuite() *suite {
 
 	accounts, err := sandbox.getAccounts()
 	if err != nil {
-		fmt.Printf("%+v", err)
-		os.Exit(1)
+		t.Fatalf("%+v", err)
+	}
+
+	err = saveTestAccounts(accounts, cfg.KeystorePassphrase)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// The first account deploys the apps, the others play.
+	clientAccounts := []client.Account{}
+	for i, acc := range accounts {
+		role := client.RolePlayer
+		if i == 0 {
+			role = client.RoleAdmin
+		}
+
+		clientAccounts = append(clientAccounts, client.NewAccount(fmt.Sprintf("account-%d", i), role, acc))
 	}
 
-	err = saveTestAccounts(accounts)
+	// The operations only sign for the network of the profile, the one of the sandbox.
+	version, err := algodClient.Versions().Do(context.Background())
 	if err != nil {
-		fmt.Printf("%+v", err)
-		os.Exit(1)
+		t.Fatalf("%+v", err)
 	}
 
+	profile := client.Profile{GenesisID: version.GenesisID, GenesisHash: base64.StdEncoding.EncodeToString(version.GenesisHash)}
+	profile.Algod.Endpoint = cfg.AlgodEndpoint
+	profile.Algod.APIToken = cfg.AlgodToken
+
 	return &suite{
 		Algod:    algodClient,
-		Accounts: accounts,
+		Accounts: clientAccounts,
+		Ctx:      client.WithProfile(client.WithLogger(context.Background(), logger), profile),
+		Logger:   logger,
 	}
 }
 
-func saveTestAccounts(accounts []crypto.Account) error {
-	buf := bytes.NewBuffer(nil)
+// saveTestAccounts saves the accounts in an encrypted keystore so they can be used with the CLI.
+func saveTestAccounts(accounts []crypto.Account, passphrase string) error {
+	if passphrase == "" {
+		return nil
+	}
+
+	path := "latest-generated-accounts.json"
+
+	// Start from scratch, the sandbox accounts change when it is reset.
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+
+	keystore, err := client.OpenKeystore(path)
+	if err != nil {
+		return err
+	}
+
 	for i, acc := range accounts {
-		words, err := mnemonic.FromPrivateKey(acc.PrivateKey)
+		err = keystore.Import(fmt.Sprintf("account-%d", i), acc.PrivateKey, []byte(passphrase))
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
-
-		buf.WriteString(fmt.Sprintf("Account %d: %s", i, words))
 	}
 
-	err := os.WriteFile(
-		"latest-generated-accounts",
-		buf.Bytes(),
-		fs.ModePerm,
-	)
-
-	return errors.WithStack(err)
+	return nil
 }
 
 func (s *suite) getAccountsBalances() map[string]uint64 {
@@ -112,7 +161,7 @@ func (s *suite) getAccountsBalances() map[string]uint64 {
 	for _, acc := range s.Accounts {
 		account := acc
 		g.Go(func() error {
-			info, err := s.Algod.AccountInformation(account.Address.String()).Do(context.Background())
+			info, err := s.Algod.AccountInformation(account.Address.String()).Do(s.Ctx)
 			if err != nil {
 				return err
 			}
@@ -136,7 +185,7 @@ func (s *suite) getContractAccountInfo(appID uint64) models.Account {
 	account := crypto.GetApplicationAddress(appID)
 
 	// This still doesn't return the min-balance https://github.com/algorand/go-algorand-sdk/issues/272
-	info, err := s.Algod.AccountInformation(account.String()).Do(context.Background())
+	info, err := s.Algod.AccountInformation(account.String()).Do(s.Ctx)
 	if err != nil {
 		panic(err)
 	}
@@ -150,7 +199,7 @@ func (s *suite) minBalance() uint64 {
 }
 
 func (s *suite) getSuggestedParams() types.SuggestedParams {
-	suggestedParams, err := s.Algod.SuggestedParams().Do(context.Background())
+	suggestedParams, err := s.Algod.SuggestedParams().Do(s.Ctx)
 	if err != nil {
 		panic(err)
 	}
@@ -160,3 +209,58 @@ func (s *suite) getSuggestedParams() types.SuggestedParams {
 func multiplyPercentage(amount uint64, percentage uint64) uint64 {
 	return uint64(math.Ceil(float64(amount) * float64(percentage) / 100))
 }
+
+func (s *suite) sendTx(sender client.Account, tx types.Transaction) models.PendingTransactionInfoResponse {
+	signed, err := sender.Signer.SignTransactions([]types.Transaction{tx}, []int{0})
+	if err != nil {
+		panic(err)
+	}
+
+	txID, err := s.Algod.SendRawTransaction(signed[0]).Do(s.Ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	resp, err := transaction.WaitForConfirmation(s.Algod, txID, 3, s.Ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	return resp
+}
+
+func (s *suite) createAsset(creator client.Account, total uint64) uint64 {
+	tx, err := transaction.MakeAssetCreateTxn(creator.Address.String(), nil, s.getSuggestedParams(), total, 0, false, "", "", "", "", "CROWN", "Crown", "", "")
+	if err != nil {
+		panic(err)
+	}
+
+	return s.sendTx(creator, tx).AssetIndex
+}
+
+func (s *suite) optInAsset(account client.Account, assetID uint64) {
+	tx, err := transaction.MakeAssetAcceptanceTxn(account.Address.String(), nil, s.getSuggestedParams(), assetID)
+	if err != nil {
+		panic(err)
+	}
+
+	s.sendTx(account, tx)
+}
+
+func (s *suite) transferAsset(from client.Account, to client.Account, assetID uint64, amount uint64) {
+	tx, err := transaction.MakeAssetTransferTxn(from.Address.String(), to.Address.String(), amount, nil, s.getSuggestedParams(), "", assetID)
+	if err != nil {
+		panic(err)
+	}
+
+	s.sendTx(from, tx)
+}
+
+func (s *suite) getAssetBalance(address string, assetID uint64) uint64 {
+	info, err := s.Algod.AccountAssetInformation(address, assetID).Do(s.Ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	return info.AssetHolding.Amount
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUpgrade(t *testing.T) {
+	Convey("Timelocked upgrades and deletes", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+
+		approvalSource, clearSource, err := contracts.Programs(contracts.Version)
+		So(err, ShouldBeNil)
+
+		approvalProgram, clearProgram, hash, err := client.CompileUpgrade(s.Ctx, s.Algod, approvalSource, clearSource)
+		So(err, ShouldBeNil)
+
+		Convey("Without a delay", func() {
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+			So(err, ShouldBeNil)
+
+			Convey("Refuses an upgrade that wasn't announced", func() {
+				err := client.ExecuteUpgrade(s.Ctx, s.Algod, owner, appID, approvalProgram, clearProgram, 3)
+				So(errors.Is(err, client.ErrNoUpgrade), ShouldBeTrue)
+			})
+
+			Convey("Refuses to delete before the delete is announced", func() {
+				_, err := client.Decommission(s.Ctx, s.Algod, owner, appID, false, 3)
+				So(errors.Is(err, client.ErrNoUpgrade), ShouldBeTrue)
+			})
+
+			Convey("Upgrades to the announced programs", func() {
+				upgrade, err := client.AnnounceUpgrade(s.Ctx, s.Algod, owner, appID, approvalProgram, clearProgram, 3)
+				So(err, ShouldBeNil)
+				So(upgrade.Delete, ShouldBeFalse)
+				So(upgrade.Hash, ShouldResemble, hash)
+
+				Convey("Only by the admin", func() {
+					err := client.ExecuteUpgrade(s.Ctx, s.Algod, first, appID, approvalProgram, clearProgram, 3)
+					So(errors.Is(err, client.ErrNotAdmin), ShouldBeTrue)
+				})
+
+				Convey("Clears the announce", func() {
+					err := client.ExecuteUpgrade(s.Ctx, s.Algod, owner, appID, approvalProgram, clearProgram, 3)
+					So(err, ShouldBeNil)
+
+					state, err := client.GetAppState(s.Ctx, s.Algod, appID)
+					So(err, ShouldBeNil)
+					So(state.PendingUpgrade, ShouldBeNil)
+				})
+			})
+		})
+
+		Convey("With a delay", func() {
+			appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, time.Hour, "")
+			So(err, ShouldBeNil)
+
+			state, err := client.GetAppState(s.Ctx, s.Algod, appID)
+			So(err, ShouldBeNil)
+			So(state.UpgradeDelay, ShouldEqual, time.Hour)
+
+			Convey("Refuses the upgrade until the delay passed", func() {
+				upgrade, err := client.AnnounceUpgrade(s.Ctx, s.Algod, owner, appID, approvalProgram, clearProgram, 3)
+				So(err, ShouldBeNil)
+				So(upgrade.Ready(), ShouldBeFalse)
+
+				err = client.ExecuteUpgrade(s.Ctx, s.Algod, owner, appID, approvalProgram, clearProgram, 3)
+				So(errors.Is(err, client.ErrTimelocked), ShouldBeTrue)
+			})
+
+			Convey("Refuses the delete until the delay passed", func() {
+				_, err := client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldBeNil)
+
+				_, err = client.Decommission(s.Ctx, s.Algod, owner, appID, false, 3)
+				So(errors.Is(err, client.ErrTimelocked), ShouldBeTrue)
+
+				_, err = s.Algod.GetApplicationByID(appID).Do(s.Ctx)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Shows the announce to the watchers until it's cancelled", func() {
+				_, err := client.AnnounceDelete(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldBeNil)
+
+				upgrades := client.ListPendingUpgrades(s.Ctx, s.Algod, []uint64{appID}, client.StatesOptions{})
+				So(upgrades, ShouldHaveLength, 1)
+				So(upgrades[0].Err, ShouldBeNil)
+				So(upgrades[0].Upgrade.Delete, ShouldBeTrue)
+
+				err = client.CancelUpgrade(s.Ctx, s.Algod, owner, appID, 3)
+				So(err, ShouldBeNil)
+
+				upgrades = client.ListPendingUpgrades(s.Ctx, s.Algod, []uint64{appID}, client.StatesOptions{})
+				So(upgrades, ShouldHaveLength, 0)
+			})
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/qrksp/king-of-algo/client"
+	"github.com/qrksp/king-of-algo/contracts"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestVerifyApp(t *testing.T) {
+	Convey("client.VerifyApp()", t, func() {
+		s := NewSuite(t)
+
+		owner := s.Accounts[0]
+		first := s.Accounts[1]
+
+		appID, err := client.Deploy(s.Ctx, s.Algod, owner, nil, "", time.Hour, 0, "")
+		So(err, ShouldBeNil)
+
+		Convey("Matches the local sources", func() {
+			result, err := client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, owner.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(result.OK(), ShouldBeTrue)
+			So(result.ApprovalDiff, ShouldBeEmpty)
+			So(result.Creator, ShouldEqual, owner.Address.String())
+		})
+
+		Convey("Fails when the creator isn't the expected one", func() {
+			result, err := client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, first.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(result.OK(), ShouldBeFalse)
+			So(result.CreatorMatch, ShouldBeFalse)
+		})
+
+		Convey("Detects an app deployed with the v1 contract", func() {
+			approvalSource, clearSource, err := contracts.Programs("v1")
+			So(err, ShouldBeNil)
+
+			approvalProgram, clearProgram, _, err := client.CompileUpgrade(s.Ctx, s.Algod, approvalSource, clearSource)
+			So(err, ShouldBeNil)
+
+			reignPeriod := make([]byte, 8)
+			binary.BigEndian.PutUint64(reignPeriod, 3600)
+
+			tx, err := transaction.MakeApplicationCreateTx(
+				false,
+				approvalProgram,
+				clearProgram,
+				types.StateSchema{NumUint: 6, NumByteSlice: 2},
+				types.StateSchema{},
+				[][]byte{reignPeriod},
+				nil,
+				nil,
+				nil,
+				s.getSuggestedParams(),
+				owner.Address,
+				nil,
+				types.Digest{},
+				[32]byte{},
+				types.ZeroAddress,
+			)
+			So(err, ShouldBeNil)
+			v1AppID := s.sendTx(owner, tx).ApplicationIndex
+
+			result, err := client.VerifyApp(s.Ctx, s.Algod, v1AppID, contracts.Version, owner.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(result.OK(), ShouldBeFalse)
+			So(result.Detected, ShouldEqual, "v1")
+
+			result, err = client.VerifyApp(s.Ctx, s.Algod, v1AppID, "v1", owner.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(result.OK(), ShouldBeTrue)
+			So(result.Detected, ShouldEqual, "v1")
+
+			result, err = client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, owner.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(result.Detected, ShouldEqual, contracts.Version)
+		})
+
+		Convey("Matches the admin the creator handed over to", func() {
+			err := client.ProposeAdmin(s.Ctx, s.Algod, owner, appID, first.Address.String(), 3)
+			So(err, ShouldBeNil)
+
+			err = client.AcceptAdmin(s.Ctx, s.Algod, first, appID, 3)
+			So(err, ShouldBeNil)
+
+			// The admin isn't the creator anymore.
+			result, err := client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, owner.Address.String(), "")
+			So(err, ShouldBeNil)
+			So(result.Admin, ShouldEqual, first.Address.String())
+			So(result.AdminMatch, ShouldBeFalse)
+			So(result.OK(), ShouldBeFalse)
+
+			result, err = client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, owner.Address.String(), first.Address.String())
+			So(err, ShouldBeNil)
+			So(result.AdminMatch, ShouldBeTrue)
+			So(result.OK(), ShouldBeTrue)
+
+			result, err = client.VerifyApp(s.Ctx, s.Algod, appID, contracts.Version, owner.Address.String(), owner.Address.String())
+			So(err, ShouldBeNil)
+			So(result.AdminMatch, ShouldBeFalse)
+		})
+	})
+}